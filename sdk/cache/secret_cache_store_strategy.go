@@ -1,12 +1,15 @@
 package cache
 
 import (
+	"container/list"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
@@ -17,6 +20,9 @@ import (
 const (
 	JsonFileNamePrefix = "stage_"
 	JsonFileNameSuffix = ".json"
+
+	// cacheKeyStageSeparator 凭据名称与Version Stage的分隔符，凭据名称中不允许出现该字符
+	cacheKeyStageSeparator = "#"
 )
 
 // SecretCacheStoreStrategy 缓存secret策略
@@ -34,6 +40,49 @@ type SecretCacheStoreStrategy interface {
 	Close() error
 }
 
+// StageSecretCacheStoreStrategy 支持同时缓存同一凭据多个Version Stage的缓存策略
+type StageSecretCacheStoreStrategy interface {
+	SecretCacheStoreStrategy
+
+	// 获取指定Version Stage的secret缓存信息
+	GetCacheSecretInfoByStage(secretName, stage string) (*models.CacheSecretInfo, error)
+}
+
+// EvictableSecretCacheStoreStrategy 支持淘汰凭据的缓存策略
+type EvictableSecretCacheStoreStrategy interface {
+	SecretCacheStoreStrategy
+
+	// 注册凭据淘汰回调，凭据从缓存中淘汰后调用
+	SetEvictionListener(listener func(secretName, stage string))
+
+	// 获取指定Version Stage的secret缓存信息，不更新凭据访问时间
+	PeekCacheSecretInfo(secretName, stage string) (*models.CacheSecretInfo, error)
+}
+
+// RemovableSecretCacheStoreStrategy 支持删除凭据的缓存策略
+type RemovableSecretCacheStoreStrategy interface {
+	SecretCacheStoreStrategy
+
+	// 删除指定Version Stage的secret缓存信息
+	RemoveCacheSecretInfo(secretName, stage string) error
+}
+
+// CacheKey 根据凭据名称和Version Stage生成缓存key，ACSCurrent的缓存key为凭据名称
+func CacheKey(secretName, stage string) string {
+	if stage == "" || stage == utils.StageAcsCurrent {
+		return secretName
+	}
+	return secretName + cacheKeyStageSeparator + stage
+}
+
+// ParseCacheKey 将缓存key解析为凭据名称和Version Stage
+func ParseCacheKey(key string) (secretName, stage string) {
+	if index := strings.Index(key, cacheKeyStageSeparator); index >= 0 {
+		return key[:index], key[index+len(cacheKeyStageSeparator):]
+	}
+	return key, utils.StageAcsCurrent
+}
+
 type FileCacheSecretStoreStrategy struct {
 	// 缓存凭据文件路径
 	CacheSecretPath string
@@ -47,6 +96,26 @@ type FileCacheSecretStoreStrategy struct {
 
 type MemoryCacheSecretStoreStrategy struct {
 	CacheSecretInfoMap cmap.ConcurrentMap
+	// 最大缓存凭据数量，超过后淘汰最近最少访问的凭据，小于等于0表示不限制
+	MaxEntries int
+	// 凭据最大空闲时间，超过该时间未被访问的凭据将被淘汰，单位MS，小于等于0表示不淘汰
+	IdleTimeout int64
+	// 获取当前时间，为nil时使用time.Now
+	Clock func() time.Time
+
+	lruMtx           sync.Mutex
+	lruList          *list.List
+	lruElementMap    map[string]*list.Element
+	evictionListener func(secretName, stage string)
+	closeCh          chan struct{}
+	closeOnce        sync.Once
+}
+
+// lruEntry 凭据访问记录
+type lruEntry struct {
+	secretName          string
+	stage               string
+	lastAccessTimestamp int64
 }
 
 func NewFileCacheSecretStoreStrategy(cacheSecretPath string, reloadOnStart bool, salt string) *FileCacheSecretStoreStrategy {
@@ -65,6 +134,16 @@ func NewMemoryCacheSecretStoreStrategy() *MemoryCacheSecretStoreStrategy {
 	}
 }
 
+// NewBoundedMemoryCacheSecretStoreStrategy 构建限制缓存数量和空闲时间的内存缓存策略
+// maxEntries为最大缓存凭据数量，idleTimeout为凭据最大空闲时间，单位MS
+func NewBoundedMemoryCacheSecretStoreStrategy(maxEntries int, idleTimeout int64) *MemoryCacheSecretStoreStrategy {
+	return &MemoryCacheSecretStoreStrategy{
+		CacheSecretInfoMap: cmap.New(),
+		MaxEntries:         maxEntries,
+		IdleTimeout:        idleTimeout,
+	}
+}
+
 func (fs *FileCacheSecretStoreStrategy) Init() error {
 	if fs.CacheSecretPath == "" {
 		fs.CacheSecretPath = "."
@@ -89,7 +168,9 @@ func (fs *FileCacheSecretStoreStrategy) StoreSecret(cacheSecretInfo *models.Cach
 		return err
 	}
 	secretInfo.SecretValue = encryptedValue
-	fileName := strings.ToLower(JsonFileNamePrefix + cacheSecretInfo.Stage + JsonFileNameSuffix)
+	// 二进制凭据的原始字节不落盘，读取时由解密后的SecretValue重新解码
+	secretInfo.SecretValueByteBuffer = nil
+	fileName := fs.getFileName(cacheSecretInfo.Stage)
 	cacheSecretPath := fs.CacheSecretPath + string(os.PathSeparator) + secretInfo.SecretName
 	if utils.FileExists(cacheSecretPath, fileName) {
 		err = utils.FileDelete(cacheSecretPath, fileName)
@@ -101,23 +182,29 @@ func (fs *FileCacheSecretStoreStrategy) StoreSecret(cacheSecretInfo *models.Cach
 	if err != nil {
 		return err
 	}
-	fs.CacheSecretInfoMap.Set(secretInfo.SecretName, memoryCacheSecretInfo)
-	fs.ReloadedSet.Add(cacheSecretInfo.SecretInfo.SecretName)
+	cacheKey := CacheKey(secretInfo.SecretName, cacheSecretInfo.Stage)
+	fs.CacheSecretInfoMap.Set(cacheKey, memoryCacheSecretInfo)
+	fs.ReloadedSet.Add(cacheKey)
 	return nil
 }
 
 func (fs *FileCacheSecretStoreStrategy) GetCacheSecretInfo(secretName string) (*models.CacheSecretInfo, error) {
-	if !fs.ReloadOnStart && !fs.ReloadedSet.Contains(secretName) {
-		return nil, errors.New(fmt.Sprintf("reloadedSet can't find [%s] key", secretName))
+	return fs.GetCacheSecretInfoByStage(secretName, utils.StageAcsCurrent)
+}
+
+func (fs *FileCacheSecretStoreStrategy) GetCacheSecretInfoByStage(secretName, stage string) (*models.CacheSecretInfo, error) {
+	key := CacheKey(secretName, stage)
+	if !fs.ReloadOnStart && !fs.ReloadedSet.Contains(key) {
+		return nil, errors.New(fmt.Sprintf("reloadedSet can't find [%s] key", key))
 	}
-	if cacheSecretInfoI, ok := fs.CacheSecretInfoMap.Get(secretName); ok {
+	if cacheSecretInfoI, ok := fs.CacheSecretInfoMap.Get(key); ok {
 		if cacheSecretInfo, okk := cacheSecretInfoI.(*models.CacheSecretInfo); okk {
 			return cacheSecretInfo, nil
 		} else {
 			return nil, errors.New(fmt.Sprintf("CacheSecretInfoMap unknown type, expect: *models.CacheSecretInfo"))
 		}
 	}
-	fileName := strings.ToLower(JsonFileNamePrefix + utils.StageAcsCurrent + JsonFileNameSuffix)
+	fileName := fs.getFileName(stage)
 	cacheSecretPath := fs.CacheSecretPath + string(os.PathSeparator) + secretName
 	var cacheSecretInfo *models.CacheSecretInfo
 	err := utils.ReadJsonObject(cacheSecretPath, fileName, &cacheSecretInfo)
@@ -130,10 +217,34 @@ func (fs *FileCacheSecretStoreStrategy) GetCacheSecretInfo(secretName string) (*
 		return nil, err
 	}
 	secretInfo.SecretValue = secretValue
-	fs.CacheSecretInfoMap.Set(secretInfo.SecretName, cacheSecretInfo)
+	if utils.BinaryDataType == secretInfo.SecretDataType && len(secretInfo.SecretValueByteBuffer) == 0 {
+		if byteBuffer, err := utils.DecodeBinarySecretValue(secretValue); err == nil {
+			secretInfo.SecretValueByteBuffer = byteBuffer
+		}
+	}
+	fs.CacheSecretInfoMap.Set(key, cacheSecretInfo)
 	return cacheSecretInfo, nil
 }
 
+func (fs *FileCacheSecretStoreStrategy) RemoveCacheSecretInfo(secretName, stage string) error {
+	key := CacheKey(secretName, stage)
+	fs.CacheSecretInfoMap.Remove(key)
+	fs.ReloadedSet.Remove(key)
+	fileName := fs.getFileName(stage)
+	cacheSecretPath := fs.CacheSecretPath + string(os.PathSeparator) + secretName
+	if utils.FileExists(cacheSecretPath, fileName) {
+		return utils.FileDelete(cacheSecretPath, fileName)
+	}
+	return nil
+}
+
+func (fs *FileCacheSecretStoreStrategy) getFileName(stage string) string {
+	if stage == "" {
+		stage = utils.StageAcsCurrent
+	}
+	return strings.ToLower(JsonFileNamePrefix + stage + JsonFileNameSuffix)
+}
+
 func (fs *FileCacheSecretStoreStrategy) encryptSecretValue(secretValue string, key []byte) (string, error) {
 	iv := make([]byte, utils.IvLength)
 	_, err := rand.Read(iv)
@@ -175,26 +286,190 @@ func (fs *FileCacheSecretStoreStrategy) Close() error {
 }
 
 func (ms *MemoryCacheSecretStoreStrategy) Init() error {
-	// do something
+	if !ms.bounded() {
+		return nil
+	}
+	ms.lruMtx.Lock()
+	defer ms.lruMtx.Unlock()
+	ms.initLRU()
+	if ms.IdleTimeout > 0 && ms.closeCh == nil {
+		ms.closeCh = make(chan struct{})
+		go ms.evictIdleLoop(ms.closeCh)
+	}
 	return nil
 }
 
 func (ms *MemoryCacheSecretStoreStrategy) StoreSecret(cacheSecretInfo *models.CacheSecretInfo) error {
-	ms.CacheSecretInfoMap.Set(cacheSecretInfo.SecretInfo.SecretName, cacheSecretInfo)
+	key := CacheKey(cacheSecretInfo.SecretInfo.SecretName, cacheSecretInfo.Stage)
+	if !ms.bounded() {
+		ms.CacheSecretInfoMap.Set(key, cacheSecretInfo)
+		return nil
+	}
+	var evicted []*lruEntry
+	ms.lruMtx.Lock()
+	ms.initLRU()
+	ms.CacheSecretInfoMap.Set(key, cacheSecretInfo)
+	if _, ok := ms.lruElementMap[key]; !ok {
+		ms.lruElementMap[key] = ms.lruList.PushFront(&lruEntry{
+			secretName:          cacheSecretInfo.SecretInfo.SecretName,
+			stage:               cacheSecretInfo.Stage,
+			lastAccessTimestamp: ms.nowMillis(),
+		})
+		for ms.MaxEntries > 0 && ms.lruList.Len() > ms.MaxEntries {
+			evicted = append(evicted, ms.removeElementLocked(ms.lruList.Back()))
+		}
+	}
+	ms.lruMtx.Unlock()
+	ms.notifyEvicted(evicted)
 	return nil
 }
 
 func (ms *MemoryCacheSecretStoreStrategy) GetCacheSecretInfo(secretName string) (*models.CacheSecretInfo, error) {
-	if cacheSecretInfoI, ok := ms.CacheSecretInfoMap.Get(secretName); ok {
+	return ms.GetCacheSecretInfoByStage(secretName, utils.StageAcsCurrent)
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) GetCacheSecretInfoByStage(secretName, stage string) (*models.CacheSecretInfo, error) {
+	cacheSecretInfo, err := ms.PeekCacheSecretInfo(secretName, stage)
+	if err != nil || !ms.bounded() {
+		return cacheSecretInfo, err
+	}
+	key := CacheKey(secretName, stage)
+	now := ms.nowMillis()
+	ms.lruMtx.Lock()
+	ms.initLRU()
+	if element, ok := ms.lruElementMap[key]; ok {
+		entry := element.Value.(*lruEntry)
+		if ms.IdleTimeout > 0 && now-entry.lastAccessTimestamp > ms.IdleTimeout {
+			ms.removeElementLocked(element)
+			ms.lruMtx.Unlock()
+			ms.notifyEvicted([]*lruEntry{entry})
+			return nil, errors.New(fmt.Sprintf("invalid cacheSecretInfoMap key [%s]", key))
+		}
+		entry.lastAccessTimestamp = now
+		ms.lruList.MoveToFront(element)
+	}
+	ms.lruMtx.Unlock()
+	return cacheSecretInfo, nil
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) PeekCacheSecretInfo(secretName, stage string) (*models.CacheSecretInfo, error) {
+	key := CacheKey(secretName, stage)
+	if cacheSecretInfoI, ok := ms.CacheSecretInfoMap.Get(key); ok {
 		if cacheSecretInfo, okk := cacheSecretInfoI.(*models.CacheSecretInfo); okk {
 			return cacheSecretInfo, nil
 		} else {
 			return nil, errors.New(fmt.Sprintf("invalid type [CacheSecretInfo]"))
 		}
 	}
-	return nil, errors.New(fmt.Sprintf("invalid cacheSecretInfoMap key [%s]", secretName))
+	return nil, errors.New(fmt.Sprintf("invalid cacheSecretInfoMap key [%s]", key))
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) RemoveCacheSecretInfo(secretName, stage string) error {
+	key := CacheKey(secretName, stage)
+	if !ms.bounded() {
+		ms.CacheSecretInfoMap.Remove(key)
+		return nil
+	}
+	ms.lruMtx.Lock()
+	defer ms.lruMtx.Unlock()
+	ms.initLRU()
+	if element, ok := ms.lruElementMap[key]; ok {
+		ms.removeElementLocked(element)
+	} else {
+		ms.CacheSecretInfoMap.Remove(key)
+	}
+	return nil
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) SetEvictionListener(listener func(secretName, stage string)) {
+	ms.lruMtx.Lock()
+	defer ms.lruMtx.Unlock()
+	ms.evictionListener = listener
 }
 
 func (ms *MemoryCacheSecretStoreStrategy) Close() error {
+	ms.closeOnce.Do(func() {
+		ms.lruMtx.Lock()
+		defer ms.lruMtx.Unlock()
+		if ms.closeCh != nil {
+			close(ms.closeCh)
+		}
+	})
 	return nil
 }
+
+func (ms *MemoryCacheSecretStoreStrategy) nowMillis() int64 {
+	if ms.Clock != nil {
+		return ms.Clock().UnixNano() / 1e6
+	}
+	return time.Now().UnixNano() / 1e6
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) bounded() bool {
+	return ms.MaxEntries > 0 || ms.IdleTimeout > 0
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) initLRU() {
+	if ms.lruList == nil {
+		ms.lruList = list.New()
+		ms.lruElementMap = make(map[string]*list.Element)
+	}
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) removeElementLocked(element *list.Element) *lruEntry {
+	entry := ms.lruList.Remove(element).(*lruEntry)
+	key := CacheKey(entry.secretName, entry.stage)
+	delete(ms.lruElementMap, key)
+	ms.CacheSecretInfoMap.Remove(key)
+	return entry
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) notifyEvicted(entries []*lruEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	ms.lruMtx.Lock()
+	listener := ms.evictionListener
+	ms.lruMtx.Unlock()
+	if listener == nil {
+		return
+	}
+	for _, entry := range entries {
+		listener(entry.secretName, entry.stage)
+	}
+}
+
+// evictIdleLoop 定期淘汰空闲凭据，避免长期未访问的凭据一直被刷新
+func (ms *MemoryCacheSecretStoreStrategy) evictIdleLoop(closeCh <-chan struct{}) {
+	interval := time.Duration(ms.IdleTimeout/2) * time.Millisecond
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closeCh:
+			return
+		case <-ticker.C:
+			ms.evictIdle()
+		}
+	}
+}
+
+func (ms *MemoryCacheSecretStoreStrategy) evictIdle() {
+	var evicted []*lruEntry
+	now := ms.nowMillis()
+	ms.lruMtx.Lock()
+	for element := ms.lruList.Back(); element != nil; {
+		entry := element.Value.(*lruEntry)
+		if now-entry.lastAccessTimestamp <= ms.IdleTimeout {
+			break
+		}
+		prev := element.Prev()
+		evicted = append(evicted, ms.removeElementLocked(element))
+		element = prev
+	}
+	ms.lruMtx.Unlock()
+	ms.notifyEvicted(evicted)
+}
@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrClientClosed 客户端已关闭
+var ErrClientClosed = errors.New("secret cache client is closed")
+
+// errSecretEvicted 刷新期间凭据被缓存策略淘汰，刷新结果已丢弃
+var errSecretEvicted = errors.New("secret evicted from cache during refresh")
+
+// SecretNotJSONError 凭据值不是合法的JSON格式或无法解析为指定类型
+type SecretNotJSONError struct {
+	SecretName string
+	Err        error
+}
+
+func (e *SecretNotJSONError) Error() string {
+	return fmt.Sprintf("the secret named[%s] value is not valid json, %v", e.SecretName, e.Err)
+}
+
+func (e *SecretNotJSONError) Unwrap() error {
+	return e.Err
+}
+
+// SecretFieldNotFoundError 凭据JSON值中不存在指定字段
+type SecretFieldNotFoundError struct {
+	SecretName string
+	FieldPath  string
+}
+
+func (e *SecretFieldNotFoundError) Error() string {
+	return fmt.Sprintf("the secret named[%s] do not have field[%s]", e.SecretName, e.FieldPath)
+}
+
+// SecretTypeMismatchError 凭据类型与访问方法要求的类型不一致
+type SecretTypeMismatchError struct {
+	SecretName    string
+	ExpectedTypes []string
+	ActualType    string
+}
+
+func (e *SecretTypeMismatchError) Error() string {
+	return fmt.Sprintf("the secret named[%s] type is [%s], expected [%s]", e.SecretName, e.ActualType, strings.Join(e.ExpectedTypes, ","))
+}
+
+// PreloadSecretsError 必须加载的凭据在初始化时预加载失败
+type PreloadSecretsError struct {
+	Failed map[string]error
+}
+
+func (e *PreloadSecretsError) Error() string {
+	secretNames := e.secretNames()
+	messages := make([]string, 0, len(secretNames))
+	for _, secretName := range secretNames {
+		messages = append(messages, fmt.Sprintf("%s: %v", secretName, e.Failed[secretName]))
+	}
+	return fmt.Sprintf("the required secrets[%s] preload failed, %s", strings.Join(secretNames, ","), strings.Join(messages, "; "))
+}
+
+// Unwrap 返回按凭据名称排序的第一个错误
+func (e *PreloadSecretsError) Unwrap() error {
+	secretNames := e.secretNames()
+	if len(secretNames) == 0 {
+		return nil
+	}
+	return e.Failed[secretNames[0]]
+}
+
+func (e *PreloadSecretsError) secretNames() []string {
+	secretNames := make([]string, 0, len(e.Failed))
+	for secretName := range e.Failed {
+		secretNames = append(secretNames, secretName)
+	}
+	sort.Strings(secretNames)
+	return secretNames
+}
+
+// OptionsError New的配置项校验失败，包含所有不合法的配置
+type OptionsError struct {
+	Errs []error
+}
+
+func (e *OptionsError) Error() string {
+	messages := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("invalid options: %s", strings.Join(messages, "; "))
+}
@@ -108,23 +108,28 @@ func (cl *CommonLogger) Flush() {
 }
 
 func (cl *CommonLogger) Tracef(format string, params ...interface{}) {
-	cl.wrapper.Tracef(cl.parseExceptionErrorMsg(format, params...), params...)
+	format, params = scrubMessage(cl.parseExceptionErrorMsg(format, params...), params)
+	cl.wrapper.Tracef(format, params...)
 }
 
 func (cl *CommonLogger) Infof(format string, params ...interface{}) {
-	cl.wrapper.Infof(cl.parseExceptionErrorMsg(format, params...), params...)
+	format, params = scrubMessage(cl.parseExceptionErrorMsg(format, params...), params)
+	cl.wrapper.Infof(format, params...)
 }
 
 func (cl *CommonLogger) Debugf(format string, params ...interface{}) {
-	cl.wrapper.Debugf(cl.parseExceptionErrorMsg(format, params...), params...)
+	format, params = scrubMessage(cl.parseExceptionErrorMsg(format, params...), params)
+	cl.wrapper.Debugf(format, params...)
 }
 
 func (cl *CommonLogger) Warnf(format string, params ...interface{}) {
-	cl.wrapper.Warnf(cl.parseExceptionErrorMsg(format, params...), params...)
+	format, params = scrubMessage(cl.parseExceptionErrorMsg(format, params...), params)
+	cl.wrapper.Warnf(format, params...)
 }
 
 func (cl *CommonLogger) Errorf(format string, params ...interface{}) {
-	cl.wrapper.Errorf(cl.parseExceptionErrorMsg(format, params...), params...)
+	format, params = scrubMessage(cl.parseExceptionErrorMsg(format, params...), params)
+	cl.wrapper.Errorf(format, params...)
 }
 
 func (cl *CommonLogger) parseExceptionErrorMsg(format string, params ...interface{}) string {
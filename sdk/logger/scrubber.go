@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// RedactedValue 日志中凭据值的替换文本
+	RedactedValue = "******"
+
+	// MinScrubValueLength 参与脱敏的最小凭据值长度，过短的值会误伤正常日志
+	MinScrubValueLength = 4
+)
+
+var defaultScrubber = NewScrubber()
+
+// Scrubber 日志脱敏器，将已登记的凭据值替换为RedactedValue
+type Scrubber struct {
+	mtx sync.RWMutex
+	// 已登记的凭据值及其登记次数
+	values map[string]int
+	// 按长度降序排列的凭据值，优先替换较长的值
+	sorted []string
+}
+
+func NewScrubber() *Scrubber {
+	return &Scrubber{values: make(map[string]int)}
+}
+
+// GetScrubber 获取所有CommonLogger共用的脱敏器
+func GetScrubber() *Scrubber {
+	return defaultScrubber
+}
+
+// Add 登记需要脱敏的凭据值，同一值可重复登记，需调用相同次数的Remove才会移除
+func (s *Scrubber) Add(values ...string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	changed := false
+	for _, value := range values {
+		if len(value) < MinScrubValueLength {
+			continue
+		}
+		if s.values[value] == 0 {
+			changed = true
+		}
+		s.values[value]++
+	}
+	if changed {
+		s.sortLocked()
+	}
+}
+
+// Remove 移除已登记的凭据值
+func (s *Scrubber) Remove(values ...string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	changed := false
+	for _, value := range values {
+		count, ok := s.values[value]
+		if !ok {
+			continue
+		}
+		if count <= 1 {
+			delete(s.values, value)
+			changed = true
+		} else {
+			s.values[value] = count - 1
+		}
+	}
+	if changed {
+		s.sortLocked()
+	}
+}
+
+// Len 返回已登记的凭据值数量
+func (s *Scrubber) Len() int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return len(s.sorted)
+}
+
+// Scrub 将msg中已登记的凭据值替换为RedactedValue
+func (s *Scrubber) Scrub(msg string) string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for _, value := range s.sorted {
+		msg = strings.Replace(msg, value, RedactedValue, -1)
+	}
+	return msg
+}
+
+func (s *Scrubber) sortLocked() {
+	sorted := make([]string, 0, len(s.values))
+	for value := range s.values {
+		sorted = append(sorted, value)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+	s.sorted = sorted
+}
+
+// scrubMessage 存在已登记的凭据值时格式化日志并脱敏，否则保持原格式及参数
+func scrubMessage(format string, params []interface{}) (string, []interface{}) {
+	if defaultScrubber.Len() == 0 {
+		return format, params
+	}
+	return "%s", []interface{}{defaultScrubber.Scrub(fmt.Sprintf(format, params...))}
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubber(t *testing.T) {
+	scrubber := NewScrubber()
+	scrubber.Add("abc", "password", "password-long")
+	assert.Equal(t, 2, scrubber.Len())
+	assert.Equal(t, "abc ****** ******", scrubber.Scrub("abc password password-long"))
+
+	scrubber.Add("password")
+	scrubber.Remove("password")
+	assert.Equal(t, "abc ******", scrubber.Scrub("abc password"))
+	scrubber.Remove("password", "password-long", "unknown")
+	assert.Equal(t, 0, scrubber.Len())
+	assert.Equal(t, "abc password", scrubber.Scrub("abc password"))
+}
+
+func TestCommonLogger_Scrub(t *testing.T) {
+	var buf bytes.Buffer
+	l := &CommonLogger{wrapper: NewDefaultLogger(log.New(&buf, "", 0)), modeName: "CacheClient"}
+	GetScrubber().Add("scrubbed-secret-value")
+	defer GetScrubber().Remove("scrubbed-secret-value")
+	l.Infof("value:%s, %d", "scrubbed-secret-value", 1)
+	assert.Equal(t, "[Info] value:******, 1\n", buf.String())
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/alibabacloud-go/tea/dara"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+const (
+	// OutcomeSuccess 成功
+	OutcomeSuccess = "success"
+	// OutcomeFailure 失败
+	OutcomeFailure = "failure"
+	// ErrorCodeOK 调用成功时的错误码
+	ErrorCodeOK = "OK"
+	// ErrorCodeUnknown 无法识别的错误码
+	ErrorCodeUnknown = "Unknown"
+)
+
+// Metrics 凭据客户端指标采集接口，实现需保证并发安全
+type Metrics interface {
+	// IncCacheHit 缓存命中
+	IncCacheHit()
+
+	// IncCacheMiss 缓存未命中
+	IncCacheMiss()
+
+	// ObserveRefresh 记录凭据刷新耗时及结果，err为nil表示刷新成功
+	ObserveRefresh(secretName string, duration time.Duration, err error)
+
+	// ObserveKMSCall 记录单次KMS调用的地域、耗时及错误码，成功时errorCode为ErrorCodeOK
+	ObserveKMSCall(regionId string, duration time.Duration, errorCode string)
+
+	// IncRetry 记录一次KMS重试调用
+	IncRetry(regionId string)
+}
+
+// NoopMetrics 不采集任何指标
+type NoopMetrics struct {
+}
+
+func NewNoopMetrics() Metrics {
+	return &NoopMetrics{}
+}
+
+func (nm *NoopMetrics) IncCacheHit() {
+}
+
+func (nm *NoopMetrics) IncCacheMiss() {
+}
+
+func (nm *NoopMetrics) ObserveRefresh(secretName string, duration time.Duration, err error) {
+}
+
+func (nm *NoopMetrics) ObserveKMSCall(regionId string, duration time.Duration, errorCode string) {
+}
+
+func (nm *NoopMetrics) IncRetry(regionId string) {
+}
+
+// ErrorCode 解析错误对应的错误码
+func ErrorCode(err error) string {
+	if err == nil {
+		return ErrorCodeOK
+	}
+	var sdkErr *dara.SDKError
+	if errors.As(err, &sdkErr) && tea.StringValue(sdkErr.GetCode()) != "" {
+		return tea.StringValue(sdkErr.GetCode())
+	}
+	var teaErr *tea.SDKError
+	if errors.As(err, &teaErr) && tea.StringValue(teaErr.Code) != "" {
+		return tea.StringValue(teaErr.Code)
+	}
+	return ErrorCodeUnknown
+}
@@ -0,0 +1,242 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	metricNamePrefix = "secretsmanager_client_"
+	// prometheusContentType Prometheus文本格式的Content-Type
+	prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// DefaultDurationBuckets 耗时直方图默认分桶，单位秒
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// PrometheusMetrics 以Prometheus文本格式输出指标，同时实现http.Handler
+type PrometheusMetrics struct {
+	mtx                 sync.Mutex
+	cacheHits           *counterVec
+	cacheMisses         *counterVec
+	refreshes           *counterVec
+	refreshDuration     *histogramVec
+	kmsRequests         *counterVec
+	kmsRequestDurations *histogramVec
+	kmsRetries          *counterVec
+}
+
+// NewPrometheusMetrics 构建Prometheus指标采集器，buckets为空时使用DefaultDurationBuckets
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+	return &PrometheusMetrics{
+		cacheHits:           newCounterVec("cache_hits_total", "Total number of secret cache hits."),
+		cacheMisses:         newCounterVec("cache_misses_total", "Total number of secret cache misses."),
+		refreshes:           newCounterVec("refresh_total", "Total number of secret refreshes by outcome.", "secret_name", "outcome"),
+		refreshDuration:     newHistogramVec("refresh_duration_seconds", "Secret refresh latency in seconds.", sorted, "secret_name"),
+		kmsRequests:         newCounterVec("kms_requests_total", "Total number of KMS GetSecretValue calls by region and error code.", "region_id", "error_code"),
+		kmsRequestDurations: newHistogramVec("kms_request_duration_seconds", "KMS GetSecretValue call latency in seconds.", sorted, "region_id"),
+		kmsRetries:          newCounterVec("kms_retries_total", "Total number of KMS GetSecretValue retries.", "region_id"),
+	}
+}
+
+func (pm *PrometheusMetrics) IncCacheHit() {
+	pm.mtx.Lock()
+	defer pm.mtx.Unlock()
+	pm.cacheHits.add(1)
+}
+
+func (pm *PrometheusMetrics) IncCacheMiss() {
+	pm.mtx.Lock()
+	defer pm.mtx.Unlock()
+	pm.cacheMisses.add(1)
+}
+
+func (pm *PrometheusMetrics) ObserveRefresh(secretName string, duration time.Duration, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailure
+	}
+	pm.mtx.Lock()
+	defer pm.mtx.Unlock()
+	pm.refreshes.add(1, secretName, outcome)
+	pm.refreshDuration.observe(duration.Seconds(), secretName)
+}
+
+func (pm *PrometheusMetrics) ObserveKMSCall(regionId string, duration time.Duration, errorCode string) {
+	pm.mtx.Lock()
+	defer pm.mtx.Unlock()
+	pm.kmsRequests.add(1, regionId, errorCode)
+	pm.kmsRequestDurations.observe(duration.Seconds(), regionId)
+}
+
+func (pm *PrometheusMetrics) IncRetry(regionId string) {
+	pm.mtx.Lock()
+	defer pm.mtx.Unlock()
+	pm.kmsRetries.add(1, regionId)
+}
+
+// WriteTo 以Prometheus文本格式输出所有指标
+func (pm *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	pm.mtx.Lock()
+	pm.cacheHits.write(&buf)
+	pm.cacheMisses.write(&buf)
+	pm.refreshes.write(&buf)
+	pm.refreshDuration.write(&buf)
+	pm.kmsRequests.write(&buf)
+	pm.kmsRequestDurations.write(&buf)
+	pm.kmsRetries.write(&buf)
+	pm.mtx.Unlock()
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP 实现http.Handler，用于暴露指标采集端点
+func (pm *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+	_, _ = pm.WriteTo(w)
+}
+
+type counterVec struct {
+	name       string
+	help       string
+	labelNames []string
+	values     map[string]float64
+	labels     map[string][]string
+}
+
+func newCounterVec(name, help string, labelNames ...string) *counterVec {
+	return &counterVec{
+		name:       metricNamePrefix + name,
+		help:       help,
+		labelNames: labelNames,
+		values:     make(map[string]float64),
+		labels:     make(map[string][]string),
+	}
+}
+
+func (cv *counterVec) add(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	if _, ok := cv.labels[key]; !ok {
+		cv.labels[key] = labelValues
+	}
+	cv.values[key] += value
+}
+
+func (cv *counterVec) write(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", cv.name, cv.help)
+	fmt.Fprintf(buf, "# TYPE %s counter\n", cv.name)
+	if len(cv.labelNames) == 0 && len(cv.values) == 0 {
+		fmt.Fprintf(buf, "%s 0\n", cv.name)
+		return
+	}
+	for _, key := range sortedKeys(cv.labels) {
+		fmt.Fprintf(buf, "%s%s %s\n", cv.name, formatLabels(cv.labelNames, cv.labels[key], "", ""), formatFloat(cv.values[key]))
+	}
+}
+
+type histogram struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+type histogramVec struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+	histograms map[string]*histogram
+}
+
+func newHistogramVec(name, help string, buckets []float64, labelNames ...string) *histogramVec {
+	return &histogramVec{
+		name:       metricNamePrefix + name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    buckets,
+		histograms: make(map[string]*histogram),
+	}
+}
+
+func (hv *histogramVec) observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	h, ok := hv.histograms[key]
+	if !ok {
+		h = &histogram{labelValues: labelValues, counts: make([]uint64, len(hv.buckets))}
+		hv.histograms[key] = h
+	}
+	for i, bucket := range hv.buckets {
+		if value <= bucket {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+func (hv *histogramVec) write(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", hv.name, hv.help)
+	fmt.Fprintf(buf, "# TYPE %s histogram\n", hv.name)
+	keys := make([]string, 0, len(hv.histograms))
+	for key := range hv.histograms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		h := hv.histograms[key]
+		for i, bucket := range hv.buckets {
+			fmt.Fprintf(buf, "%s_bucket%s %d\n", hv.name, formatLabels(hv.labelNames, h.labelValues, "le", formatFloat(bucket)), h.counts[i])
+		}
+		fmt.Fprintf(buf, "%s_bucket%s %d\n", hv.name, formatLabels(hv.labelNames, h.labelValues, "le", "+Inf"), h.count)
+		fmt.Fprintf(buf, "%s_sum%s %s\n", hv.name, formatLabels(hv.labelNames, h.labelValues, "", ""), formatFloat(h.sum))
+		fmt.Fprintf(buf, "%s_count%s %d\n", hv.name, formatLabels(hv.labelNames, h.labelValues, "", ""), h.count)
+	}
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels 输出{name="value",...}形式的标签，extraName不为空时追加该标签
+func formatLabels(labelNames, labelValues []string, extraName, extraValue string) string {
+	if len(labelNames) == 0 && extraName == "" {
+		return ""
+	}
+	pairs := make([]string, 0, len(labelNames)+1)
+	for i, name := range labelNames {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(labelValues[i])))
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extraName, escapeLabelValue(extraValue)))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	value = strings.Replace(value, "\\", "\\\\", -1)
+	value = strings.Replace(value, "\"", "\\\"", -1)
+	return strings.Replace(value, "\n", "\\n", -1)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
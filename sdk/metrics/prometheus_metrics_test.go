@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusMetrics_WriteTo(t *testing.T) {
+	pm := NewPrometheusMetrics(0.1, 1)
+	pm.IncCacheHit()
+	pm.IncCacheHit()
+	pm.IncCacheMiss()
+	pm.ObserveRefresh("db_secret", 50*time.Millisecond, nil)
+	pm.ObserveRefresh("db_secret", 2*time.Second, errors.New("failed"))
+	pm.ObserveKMSCall("cn-hangzhou", 500*time.Millisecond, ErrorCodeOK)
+	pm.ObserveKMSCall("cn-hangzhou", 10*time.Millisecond, "Rejected.Throttling")
+	pm.IncRetry("cn-hangzhou")
+
+	var buf bytes.Buffer
+	_, err := pm.WriteTo(&buf)
+	assert.Nil(t, err)
+	output := buf.String()
+	assert.True(t, strings.Contains(output, "# TYPE secretsmanager_client_cache_hits_total counter\nsecretsmanager_client_cache_hits_total 2\n"))
+	assert.True(t, strings.Contains(output, "secretsmanager_client_cache_misses_total 1\n"))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_refresh_total{secret_name="db_secret",outcome="success"} 1`))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_refresh_total{secret_name="db_secret",outcome="failure"} 1`))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_refresh_duration_seconds_bucket{secret_name="db_secret",le="0.1"} 1`))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_refresh_duration_seconds_bucket{secret_name="db_secret",le="1"} 1`))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_refresh_duration_seconds_bucket{secret_name="db_secret",le="+Inf"} 2`))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_refresh_duration_seconds_count{secret_name="db_secret"} 2`))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_kms_requests_total{region_id="cn-hangzhou",error_code="Rejected.Throttling"} 1`))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_kms_requests_total{region_id="cn-hangzhou",error_code="OK"} 1`))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_kms_retries_total{region_id="cn-hangzhou"} 1`))
+}
+
+func TestPrometheusMetrics_ServeHTTP(t *testing.T) {
+	pm := NewPrometheusMetrics()
+	pm.ObserveRefresh("quote\"secret", time.Millisecond, nil)
+	recorder := httptest.NewRecorder()
+	pm.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, prometheusContentType, recorder.Header().Get("Content-Type"))
+	assert.True(t, strings.Contains(recorder.Body.String(), `secret_name="quote\"secret"`))
+}
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, ErrorCodeOK, ErrorCode(nil))
+	assert.Equal(t, ErrorCodeUnknown, ErrorCode(errors.New("unknown")))
+	assert.Equal(t, "Forbidden.ResourceNotFound", ErrorCode(&tea.SDKError{Code: tea.String("Forbidden.ResourceNotFound")}))
+}
@@ -4,12 +4,18 @@ type CacheSecretInfo struct {
 	SecretInfo       *SecretInfo `json:"secretInfo"`
 	Stage            string      `json:"stage"`
 	RefreshTimestamp int64       `json:"refreshTimestamp"`
+	// 刷新策略计划的下一次刷新时间戳，单位MS，缓存在该时间之前不会过期，为0时未计算
+	NextRefreshTimestamp int64 `json:"nextRefreshTimestamp,omitempty"`
+	// 获取凭据时从凭据值中解析的缓存TTL，单位MS，小于等于0时未配置
+	TTL int64 `json:"ttl,omitempty"`
 }
 
 func (csi *CacheSecretInfo) Clone() *CacheSecretInfo {
 	return &CacheSecretInfo{
-		SecretInfo:       csi.SecretInfo.Clone(),
-		Stage:            csi.Stage,
-		RefreshTimestamp: csi.RefreshTimestamp,
+		SecretInfo:           csi.SecretInfo.Clone(),
+		Stage:                csi.Stage,
+		RefreshTimestamp:     csi.RefreshTimestamp,
+		NextRefreshTimestamp: csi.NextRefreshTimestamp,
+		TTL:                  csi.TTL,
 	}
 }
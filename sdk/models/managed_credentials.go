@@ -0,0 +1,23 @@
+package models
+
+// RdsCredentials RDS、Redis、PolarDB托管凭据的凭据值
+type RdsCredentials struct {
+	AccountName     string `json:"AccountName"`
+	AccountPassword string `json:"AccountPassword"`
+}
+
+// RAMCredentials RAM托管凭据的凭据值
+type RAMCredentials struct {
+	AccessKeyId                  string `json:"AccessKeyId"`
+	AccessKeySecret              string `json:"AccessKeySecret"`
+	GenerateTimestamp            string `json:"GenerateTimestamp"`
+	PendingSecretDeleteTimestamp string `json:"PendingSecretDeleteTimestamp"`
+}
+
+// ECSCredentials ECS托管凭据的凭据值，口令方式使用Password，密钥对方式使用PublicKey和PrivateKey
+type ECSCredentials struct {
+	UserName   string `json:"UserName"`
+	Password   string `json:"Password"`
+	PublicKey  string `json:"PublicKey"`
+	PrivateKey string `json:"PrivateKey"`
+}
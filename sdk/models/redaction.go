@@ -0,0 +1,145 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RedactedValue 脱敏后的凭据值
+const RedactedValue = "******"
+
+type redactedField struct {
+	name  string
+	value string
+}
+
+// formatRedacted 按fmt的%v、%+v及%#v格式输出脱敏后的字段
+func formatRedacted(f fmt.State, verb rune, typeName string, fields []redactedField) {
+	switch verb {
+	case 'v', 's':
+		io.WriteString(f, redactedString(typeName, fields, f.Flag('+'), verb == 'v' && f.Flag('#')))
+	case 'q':
+		io.WriteString(f, strconv.Quote(redactedString(typeName, fields, false, false)))
+	default:
+		fmt.Fprintf(f, "%%!%c(%s=%s)", verb, typeName, redactedString(typeName, fields, false, false))
+	}
+}
+
+func redactedString(typeName string, fields []redactedField, withName, goSyntax bool) string {
+	var buf bytes.Buffer
+	if goSyntax {
+		buf.WriteString(typeName)
+	}
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			if goSyntax {
+				buf.WriteString(", ")
+			} else {
+				buf.WriteByte(' ')
+			}
+		}
+		if withName || goSyntax {
+			buf.WriteString(field.name)
+			buf.WriteByte(':')
+		}
+		if goSyntax {
+			buf.WriteString(strconv.Quote(field.value))
+		} else {
+			buf.WriteString(field.value)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+func redactString(value string) string {
+	if value == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+func (si SecretInfo) redactedFields() []redactedField {
+	byteBuffer := ""
+	if len(si.SecretValueByteBuffer) > 0 {
+		byteBuffer = RedactedValue
+	}
+	protectedValue := ""
+	if si.ProtectedValue != nil {
+		protectedValue = RedactedValue
+	}
+	return []redactedField{
+		{"SecretName", si.SecretName},
+		{"VersionId", si.VersionId},
+		{"SecretValue", redactString(si.SecretValue)},
+		{"SecretValueByteBuffer", byteBuffer},
+		{"SecretDataType", si.SecretDataType},
+		{"CreateTime", si.CreateTime},
+		{"SecretType", si.SecretType},
+		{"AutomaticRotation", si.AutomaticRotation},
+		{"ExtendedConfig", si.ExtendedConfig},
+		{"RotationInterval", si.RotationInterval},
+		{"NextRotationDate", si.NextRotationDate},
+		{"ProtectedValue", protectedValue},
+	}
+}
+
+// String 返回凭据值脱敏后的文本
+func (si SecretInfo) String() string {
+	return redactedString("models.SecretInfo", si.redactedFields(), true, false)
+}
+
+// Format 实现fmt.Formatter，所有格式均不输出凭据值
+func (si SecretInfo) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, "models.SecretInfo", si.redactedFields())
+}
+
+// RedactedJSON 返回凭据值脱敏后的JSON，用于日志等场景，缓存持久化仍使用json.Marshal
+func (si *SecretInfo) RedactedJSON() ([]byte, error) {
+	return json.Marshal(si.redacted())
+}
+
+func (si *SecretInfo) redacted() *SecretInfo {
+	if si == nil {
+		return nil
+	}
+	redacted := *si
+	redacted.SecretValue = redactString(si.SecretValue)
+	redacted.SecretValueByteBuffer = nil
+	return &redacted
+}
+
+func (csi CacheSecretInfo) redactedFields() []redactedField {
+	secretInfo := "<nil>"
+	if csi.SecretInfo != nil {
+		secretInfo = csi.SecretInfo.String()
+	}
+	return []redactedField{
+		{"SecretInfo", secretInfo},
+		{"Stage", csi.Stage},
+		{"RefreshTimestamp", strconv.FormatInt(csi.RefreshTimestamp, 10)},
+		{"NextRefreshTimestamp", strconv.FormatInt(csi.NextRefreshTimestamp, 10)},
+		{"TTL", strconv.FormatInt(csi.TTL, 10)},
+	}
+}
+
+// String 返回凭据值脱敏后的文本
+func (csi CacheSecretInfo) String() string {
+	return redactedString("models.CacheSecretInfo", csi.redactedFields(), true, false)
+}
+
+// Format 实现fmt.Formatter，所有格式均不输出凭据值
+func (csi CacheSecretInfo) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, "models.CacheSecretInfo", csi.redactedFields())
+}
+
+// RedactedJSON 返回凭据值脱敏后的JSON，用于日志等场景，缓存持久化仍使用json.Marshal
+func (csi *CacheSecretInfo) RedactedJSON() ([]byte, error) {
+	redacted := *csi
+	redacted.SecretInfo = csi.SecretInfo.redacted()
+	return json.Marshal(&redacted)
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretInfo_Format(t *testing.T) {
+	secretInfo := &SecretInfo{
+		SecretName:            "secret_name",
+		VersionId:             "v1",
+		SecretValue:           "secret-value",
+		SecretValueByteBuffer: []byte("secret-bytes"),
+		SecretDataType:        "text",
+	}
+	cacheSecretInfo := &CacheSecretInfo{SecretInfo: secretInfo, Stage: "ACSCurrent", RefreshTimestamp: 1}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%d"} {
+		for _, o := range []interface{}{secretInfo, *secretInfo, cacheSecretInfo, *cacheSecretInfo} {
+			text := fmt.Sprintf(format, o)
+			assert.NotContains(t, text, "secret-value", format)
+			assert.NotContains(t, text, "secret-bytes", format)
+		}
+	}
+	assert.True(t, strings.Contains(fmt.Sprintf("%+v", secretInfo), "SecretName:secret_name"))
+	assert.True(t, strings.Contains(secretInfo.String(), "SecretValue:"+RedactedValue))
+	assert.True(t, strings.Contains(fmt.Sprintf("%v", cacheSecretInfo), "ACSCurrent"))
+
+	var nilSecretInfo *SecretInfo
+	assert.Equal(t, "<nil>", fmt.Sprintf("%v", nilSecretInfo))
+}
+
+func TestSecretInfo_RedactedJSON(t *testing.T) {
+	secretInfo := &SecretInfo{SecretName: "secret_name", SecretValue: "secret-value", SecretValueByteBuffer: []byte("secret-bytes")}
+	data, err := secretInfo.RedactedJSON()
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "secret-value")
+	redacted := &SecretInfo{}
+	assert.Nil(t, json.Unmarshal(data, redacted))
+	assert.Equal(t, RedactedValue, redacted.SecretValue)
+	assert.Equal(t, "secret-value", secretInfo.SecretValue)
+
+	data, err = (&CacheSecretInfo{SecretInfo: secretInfo, Stage: "ACSCurrent"}).RedactedJSON()
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "secret-value")
+	assert.Contains(t, string(data), "ACSCurrent")
+
+	data, err = json.Marshal(secretInfo)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "secret-value")
+}
@@ -1,5 +1,7 @@
 package models
 
+import "github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/protected"
+
 type SecretInfo struct {
 	SecretName            string `json:"secretName"`
 	VersionId             string `json:"versionId"`
@@ -12,6 +14,8 @@ type SecretInfo struct {
 	ExtendedConfig        string `json:"extendedConfig"`
 	RotationInterval      string `json:"rotationInterval"`
 	NextRotationDate      string `json:"nextRotationDate"`
+	// 开启受保护凭据值时存放凭据值，此时SecretValue及SecretValueByteBuffer为空
+	ProtectedValue *protected.Buffer `json:"-"`
 }
 
 func (si *SecretInfo) Clone() *SecretInfo {
@@ -27,5 +31,6 @@ func (si *SecretInfo) Clone() *SecretInfo {
 		ExtendedConfig:        si.ExtendedConfig,
 		RotationInterval:      si.RotationInterval,
 		NextRotationDate:      si.NextRotationDate,
+		ProtectedValue:        si.ProtectedValue,
 	}
 }
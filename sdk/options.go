@@ -0,0 +1,613 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	openapiutil "github.com/alibabacloud-go/darabonba-openapi/v2/utils"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/metrics"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/service"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/tracing"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+	"github.com/aliyun/credentials-go/credentials"
+)
+
+// Option New的配置项，所有配置项的参数错误在New中统一校验并以OptionsError返回
+type Option func(o *options)
+
+type refreshJitterOption struct {
+	refreshAheadFactor float64
+	jitterFactor       float64
+}
+
+type rotationRefreshOption struct {
+	gracePeriod      time.Duration
+	fallbackInterval time.Duration
+}
+
+type backoffOption struct {
+	maxAttempts     int
+	initialInterval time.Duration
+	capacity        time.Duration
+}
+
+type boundedCacheOption struct {
+	maxEntries  int
+	idleTimeout time.Duration
+}
+
+type secretListenerOption struct {
+	secretName string
+	listener   SecretChangeListener
+}
+
+type options struct {
+	errs []error
+
+	// Secret Manager Client配置
+	secretManagerClient service.SecretManagerClient
+	credential          credentials.Credential
+	credentialSet       bool
+	regionInfos         []*models.RegionInfo
+	configs             []*openapiutil.Config
+	customConfigFile    string
+	backoff             *backoffOption
+	backoffStrategy     service.BackoffStrategy
+
+	// Cache Client配置
+	secretTTLs            map[string]time.Duration
+	jsonTTLPropertyName   string
+	refreshSecretStrategy service.RefreshSecretStrategy
+	refreshJitter         *refreshJitterOption
+	rotationRefresh       *rotationRefreshOption
+	cacheSecretStrategy   cache.SecretCacheStoreStrategy
+	boundedCache          *boundedCacheOption
+	cacheHook             cache.SecretCacheHook
+	stage                 string
+	maxStaleness          time.Duration
+	negativeCacheTTL      time.Duration
+	preloadConcurrency    int
+	refreshWorkers        int
+	initFailurePolicy     InitFailurePolicy
+	requiredSecrets       []string
+	protectedValues       bool
+	logScrubbing          bool
+	legacyBinaryValue     bool
+	secretListeners       []secretListenerOption
+	globalListeners       []SecretChangeListener
+	metrics               metrics.Metrics
+	tracer                tracing.Tracer
+	logger                logger.Wrapper
+}
+
+// New 根据配置项构建并初始化Cache Client，所有时长参数均为time.Duration
+func New(opts ...Option) (*SecretManagerCacheClient, error) {
+	o := &options{secretTTLs: make(map[string]time.Duration)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	o.validate()
+	if len(o.errs) > 0 {
+		return nil, &OptionsError{Errs: o.errs}
+	}
+	if o.logger != nil {
+		if err := logger.RegisterLogger(utils.ModeName, o.logger); err != nil {
+			return nil, err
+		}
+	} else if !logger.IsRegistered(utils.ModeName) {
+		if err := logger.RegisterLogger(utils.ModeName, logger.NewDefaultLogger(log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile))); err != nil {
+			return nil, err
+		}
+	}
+	client := o.buildClient()
+	if err := client.Init(); err != nil {
+		// 释放初始化失败前已初始化的KMS客户端等资源
+		_ = client.Close()
+		return nil, err
+	}
+	logger.GetCommonLogger(utils.ModeName).Infof("secretCacheClient new success")
+	return client, nil
+}
+
+// WithSecretManagerClient 指定Secret Manager Client，不能与凭证、地域及退避等Client配置同时使用
+func WithSecretManagerClient(client service.SecretManagerClient) Option {
+	return func(o *options) {
+		if client == nil {
+			o.addError("secretManagerClient must not be nil")
+			return
+		}
+		o.secretManagerClient = client
+	}
+}
+
+// WithAccessKey 使用AccessKey访问KMS
+func WithAccessKey(accessKeyId, accessKeySecret string) Option {
+	return func(o *options) {
+		if accessKeyId == "" || accessKeySecret == "" {
+			o.addError("accessKeyId and accessKeySecret must not be empty")
+			return
+		}
+		credential, err := utils.CredentialsWithAccessKey(accessKeyId, accessKeySecret)
+		if err != nil {
+			o.errs = append(o.errs, err)
+			return
+		}
+		o.setCredential(credential)
+	}
+}
+
+// WithCredential 指定访问KMS的凭证
+func WithCredential(credential credentials.Credential) Option {
+	return func(o *options) {
+		if credential == nil {
+			o.addError("credential must not be nil")
+			return
+		}
+		o.setCredential(credential)
+	}
+}
+
+// WithRegion 添加访问KMS的地域
+func WithRegion(regionIds ...string) Option {
+	return func(o *options) {
+		for _, regionId := range regionIds {
+			if regionId == "" {
+				o.addError("regionId must not be empty")
+				continue
+			}
+			o.regionInfos = append(o.regionInfos, &models.RegionInfo{RegionId: regionId})
+		}
+	}
+}
+
+// WithRegionInfo 添加访问KMS的地域信息
+func WithRegionInfo(regionInfo *models.RegionInfo) Option {
+	return func(o *options) {
+		if regionInfo == nil || regionInfo.RegionId == "" {
+			o.addError("regionInfo regionId must not be empty")
+			return
+		}
+		o.regionInfos = append(o.regionInfos, regionInfo)
+	}
+}
+
+// WithConfig 添加OpenAPI地域配置
+func WithConfig(config *openapiutil.Config) Option {
+	return func(o *options) {
+		if config == nil || config.RegionId == nil || *config.RegionId == "" {
+			o.addError("config regionId must not be empty")
+			return
+		}
+		o.configs = append(o.configs, config)
+	}
+}
+
+// WithCustomConfigFile 指定自定义配置文件路径
+func WithCustomConfigFile(customConfigFile string) Option {
+	return func(o *options) {
+		if customConfigFile == "" {
+			o.addError("customConfigFile must not be empty")
+			return
+		}
+		o.customConfigFile = customConfigFile
+	}
+}
+
+// WithBackoff 使用全抖动指数退避重试，initialInterval为初始重试间隔，capacity为最大等待时间
+func WithBackoff(maxAttempts int, initialInterval, capacity time.Duration) Option {
+	return func(o *options) {
+		if maxAttempts <= 0 {
+			o.addError(fmt.Sprintf("backoff maxAttempts[%d] must be positive", maxAttempts))
+		}
+		if initialInterval < time.Millisecond {
+			o.addError(fmt.Sprintf("backoff initialInterval[%v] must be at least 1ms", initialInterval))
+		}
+		if capacity < initialInterval {
+			o.addError(fmt.Sprintf("backoff capacity[%v] must not be less than initialInterval[%v]", capacity, initialInterval))
+		}
+		o.backoff = &backoffOption{maxAttempts: maxAttempts, initialInterval: initialInterval, capacity: capacity}
+	}
+}
+
+// WithBackoffStrategy 指定退避重试策略
+func WithBackoffStrategy(backoffStrategy service.BackoffStrategy) Option {
+	return func(o *options) {
+		if backoffStrategy == nil {
+			o.addError("backoffStrategy must not be nil")
+			return
+		}
+		o.backoffStrategy = backoffStrategy
+	}
+}
+
+// WithSecretTTL 设定指定凭据名称的凭据TTL并在初始化时预加载
+func WithSecretTTL(secretName string, ttl time.Duration) Option {
+	return func(o *options) {
+		if secretName == "" {
+			o.addError("secretName must not be empty")
+			return
+		}
+		if ttl < time.Millisecond {
+			o.addError(fmt.Sprintf("the secret named[%s] ttl[%v] must be at least 1ms", secretName, ttl))
+			return
+		}
+		o.secretTTLs[secretName] = ttl
+	}
+}
+
+// WithParseJSONTTL 设定secret value解析TTL字段名称
+func WithParseJSONTTL(jsonTTLPropertyName string) Option {
+	return func(o *options) {
+		if jsonTTLPropertyName == "" {
+			o.addError("jsonTTLPropertyName must not be empty")
+			return
+		}
+		o.jsonTTLPropertyName = jsonTTLPropertyName
+	}
+}
+
+// WithRefreshSecretStrategy 设定secret刷新策略
+func WithRefreshSecretStrategy(refreshSecretStrategy service.RefreshSecretStrategy) Option {
+	return func(o *options) {
+		if refreshSecretStrategy == nil {
+			o.addError("refreshSecretStrategy must not be nil")
+			return
+		}
+		o.refreshSecretStrategy = refreshSecretStrategy
+	}
+}
+
+// WithRefreshJitter 使用带随机抖动的刷新策略
+func WithRefreshJitter(refreshAheadFactor, jitterFactor float64) Option {
+	return func(o *options) {
+		if refreshAheadFactor <= 0 || refreshAheadFactor > 1 {
+			o.addError(fmt.Sprintf("refreshAheadFactor[%v] must be in (0, 1]", refreshAheadFactor))
+		}
+		if jitterFactor < 0 || jitterFactor >= 1 {
+			o.addError(fmt.Sprintf("jitterFactor[%v] must be in [0, 1)", jitterFactor))
+		}
+		o.refreshJitter = &refreshJitterOption{refreshAheadFactor: refreshAheadFactor, jitterFactor: jitterFactor}
+	}
+}
+
+// WithRotationRefresh 使用感知凭据轮转时间的刷新策略
+func WithRotationRefresh(gracePeriod, fallbackInterval time.Duration) Option {
+	return func(o *options) {
+		if gracePeriod < time.Millisecond {
+			o.addError(fmt.Sprintf("rotation gracePeriod[%v] must be at least 1ms", gracePeriod))
+		}
+		if fallbackInterval < time.Millisecond {
+			o.addError(fmt.Sprintf("rotation fallbackInterval[%v] must be at least 1ms", fallbackInterval))
+		}
+		o.rotationRefresh = &rotationRefreshOption{gracePeriod: gracePeriod, fallbackInterval: fallbackInterval}
+	}
+}
+
+// WithCacheSecretStrategy 设定secret缓存策略
+func WithCacheSecretStrategy(cacheSecretStrategy cache.SecretCacheStoreStrategy) Option {
+	return func(o *options) {
+		if cacheSecretStrategy == nil {
+			o.addError("cacheSecretStrategy must not be nil")
+			return
+		}
+		o.cacheSecretStrategy = cacheSecretStrategy
+	}
+}
+
+// WithBoundedCache 使用限制缓存数量和空闲时间的内存缓存策略，
+// maxEntries为最大缓存凭据数量，idleTimeout为凭据最大空闲时间，为0时表示不限制
+func WithBoundedCache(maxEntries int, idleTimeout time.Duration) Option {
+	return func(o *options) {
+		if maxEntries < 0 {
+			o.addError(fmt.Sprintf("bounded cache maxEntries[%d] must not be negative", maxEntries))
+		}
+		if idleTimeout != 0 && idleTimeout < time.Millisecond {
+			o.addError(fmt.Sprintf("bounded cache idleTimeout[%v] must be 0 or at least 1ms", idleTimeout))
+		}
+		o.boundedCache = &boundedCacheOption{maxEntries: maxEntries, idleTimeout: idleTimeout}
+	}
+}
+
+// WithSecretCacheHook 指定凭据Cache Hook
+func WithSecretCacheHook(hook cache.SecretCacheHook) Option {
+	return func(o *options) {
+		if hook == nil {
+			o.addError("secretCacheHook must not be nil")
+			return
+		}
+		o.cacheHook = hook
+	}
+}
+
+// WithCacheStage 指定凭据Version stage
+func WithCacheStage(stage string) Option {
+	return func(o *options) {
+		if stage == "" {
+			o.addError("stage must not be empty")
+			return
+		}
+		o.stage = stage
+	}
+}
+
+// WithStaleWhileRevalidate 开启凭据过期后返回旧值并后台刷新
+func WithStaleWhileRevalidate(maxStaleness time.Duration) Option {
+	return func(o *options) {
+		if maxStaleness < time.Millisecond {
+			o.addError(fmt.Sprintf("maxStaleness[%v] must be at least 1ms", maxStaleness))
+			return
+		}
+		o.maxStaleness = maxStaleness
+	}
+}
+
+// WithNegativeCacheTTL 设定凭据不存在或无权限等不可重试异常的缓存时长
+func WithNegativeCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		if ttl < time.Millisecond {
+			o.addError(fmt.Sprintf("negativeCacheTTL[%v] must be at least 1ms", ttl))
+			return
+		}
+		o.negativeCacheTTL = ttl
+	}
+}
+
+// WithPreloadConcurrency 设定初始化加载及批量获取凭据的最大并发数
+func WithPreloadConcurrency(concurrency int) Option {
+	return func(o *options) {
+		if concurrency <= 0 {
+			o.addError(fmt.Sprintf("preloadConcurrency[%d] must be positive", concurrency))
+			return
+		}
+		o.preloadConcurrency = concurrency
+	}
+}
+
+// WithRefreshWorkers 设定执行定时刷新的最大并发数
+func WithRefreshWorkers(workers int) Option {
+	return func(o *options) {
+		if workers <= 0 {
+			o.addError(fmt.Sprintf("refreshWorkers[%d] must be positive", workers))
+			return
+		}
+		o.refreshWorkers = workers
+	}
+}
+
+// WithInitFailurePolicy 指定初始化时预加载凭据失败的处理策略
+func WithInitFailurePolicy(policy InitFailurePolicy) Option {
+	return func(o *options) {
+		if policy < InitFailFast || policy > InitBestEffort {
+			o.addError(fmt.Sprintf("initFailurePolicy[%d] is invalid", policy))
+			return
+		}
+		o.initFailurePolicy = policy
+	}
+}
+
+// WithRequiredSecrets 指定初始化时必须加载成功的凭据，并使用InitRequireListed策略
+func WithRequiredSecrets(secretNames ...string) Option {
+	return func(o *options) {
+		for _, secretName := range secretNames {
+			if secretName == "" {
+				o.addError("required secretName must not be empty")
+				continue
+			}
+			o.requiredSecrets = append(o.requiredSecrets, secretName)
+		}
+		o.initFailurePolicy = InitRequireListed
+	}
+}
+
+// WithProtectedValues 开启受保护凭据值
+func WithProtectedValues() Option {
+	return func(o *options) {
+		o.protectedValues = true
+	}
+}
+
+// WithLogScrubbing 开启日志脱敏
+func WithLogScrubbing() Option {
+	return func(o *options) {
+		o.logScrubbing = true
+	}
+}
+
+// WithLegacyBinaryValue 二进制凭据保持返回base64文本的字节
+func WithLegacyBinaryValue() Option {
+	return func(o *options) {
+		o.legacyBinaryValue = true
+	}
+}
+
+// WithSecretChangeListener 注册指定凭据名称的变更监听器
+func WithSecretChangeListener(secretName string, listener SecretChangeListener) Option {
+	return func(o *options) {
+		if secretName == "" || listener == nil {
+			o.addError("secretChangeListener secretName and listener must not be empty")
+			return
+		}
+		o.secretListeners = append(o.secretListeners, secretListenerOption{secretName: secretName, listener: listener})
+	}
+}
+
+// WithGlobalSecretChangeListener 注册所有凭据的变更监听器
+func WithGlobalSecretChangeListener(listener SecretChangeListener) Option {
+	return func(o *options) {
+		if listener == nil {
+			o.addError("globalSecretChangeListener must not be nil")
+			return
+		}
+		o.globalListeners = append(o.globalListeners, listener)
+	}
+}
+
+// WithMetrics 指定指标采集
+func WithMetrics(m metrics.Metrics) Option {
+	return func(o *options) {
+		if m == nil {
+			o.addError("metrics must not be nil")
+			return
+		}
+		o.metrics = m
+	}
+}
+
+// WithTracer 指定链路追踪
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(o *options) {
+		if tracer == nil {
+			o.addError("tracer must not be nil")
+			return
+		}
+		o.tracer = tracer
+	}
+}
+
+// WithLogger 指定输出日志
+func WithLogger(l logger.Wrapper) Option {
+	return func(o *options) {
+		if l == nil {
+			o.addError("logger must not be nil")
+			return
+		}
+		o.logger = l
+	}
+}
+
+func (o *options) addError(message string) {
+	o.errs = append(o.errs, errors.New(message))
+}
+
+func (o *options) setCredential(credential credentials.Credential) {
+	if o.credentialSet {
+		o.addError("only one of WithAccessKey and WithCredential can be used")
+		return
+	}
+	o.credential = credential
+	o.credentialSet = true
+}
+
+// validate 校验配置项之间的冲突
+func (o *options) validate() {
+	if o.secretManagerClient != nil && o.hasServiceOptions() {
+		o.addError("WithSecretManagerClient can not be used with credential, region, config or backoff options")
+	}
+	if o.backoff != nil && o.backoffStrategy != nil {
+		o.addError("only one of WithBackoff and WithBackoffStrategy can be used")
+	}
+	refreshStrategies := 0
+	for _, set := range []bool{o.refreshSecretStrategy != nil, o.refreshJitter != nil, o.rotationRefresh != nil} {
+		if set {
+			refreshStrategies++
+		}
+	}
+	if refreshStrategies > 1 {
+		o.addError("only one of WithRefreshSecretStrategy, WithRefreshJitter and WithRotationRefresh can be used")
+	}
+	if o.cacheSecretStrategy != nil && o.boundedCache != nil {
+		o.addError("only one of WithCacheSecretStrategy and WithBoundedCache can be used")
+	}
+	if o.protectedValues {
+		if _, ok := o.cacheSecretStrategy.(*cache.FileCacheSecretStoreStrategy); ok {
+			o.addError("WithProtectedValues can not be used with FileCacheSecretStoreStrategy")
+		}
+	}
+}
+
+func (o *options) hasServiceOptions() bool {
+	return o.credentialSet || len(o.regionInfos) > 0 || len(o.configs) > 0 || o.customConfigFile != "" ||
+		o.backoff != nil || o.backoffStrategy != nil
+}
+
+// buildClient 将配置项应用到Cache Client，未指定Client配置时由Init构建默认Secret Manager Client
+func (o *options) buildClient() *SecretManagerCacheClient {
+	scc := NewSecretCacheClient()
+	if o.jsonTTLPropertyName != "" {
+		scc.jsonTTLPropertyName = o.jsonTTLPropertyName
+	}
+	if o.stage != "" {
+		scc.stage = o.stage
+	}
+	for secretName, ttl := range o.secretTTLs {
+		scc.secretTTLMap[secretName] = durationToMillis(ttl)
+	}
+	scc.metrics = o.metrics
+	scc.tracer = o.tracer
+	scc.secretManagerClient = o.secretManagerClient
+	if scc.secretManagerClient == nil && o.hasServiceOptions() {
+		scc.secretManagerClient = o.buildSecretManagerClient()
+	}
+	switch {
+	case o.refreshSecretStrategy != nil:
+		scc.refreshSecretStrategy = o.refreshSecretStrategy
+	case o.refreshJitter != nil:
+		scc.refreshSecretStrategy = service.NewJitterRefreshSecretStrategy(scc.jsonTTLPropertyName, o.refreshJitter.refreshAheadFactor, o.refreshJitter.jitterFactor)
+	case o.rotationRefresh != nil:
+		scc.refreshSecretStrategy = service.NewRotationRefreshSecretStrategy(scc.jsonTTLPropertyName,
+			durationToMillis(o.rotationRefresh.gracePeriod), durationToMillis(o.rotationRefresh.fallbackInterval))
+	}
+	scc.cacheSecretStoreStrategy = o.cacheSecretStrategy
+	if o.boundedCache != nil {
+		scc.cacheSecretStoreStrategy = cache.NewBoundedMemoryCacheSecretStoreStrategy(o.boundedCache.maxEntries, durationToMillis(o.boundedCache.idleTimeout))
+	}
+	scc.cacheHook = o.cacheHook
+	scc.maxStaleness = durationToMillis(o.maxStaleness)
+	scc.negativeCacheTTL = durationToMillis(o.negativeCacheTTL)
+	scc.preloadConcurrency = o.preloadConcurrency
+	scc.refreshWorkers = o.refreshWorkers
+	scc.initFailurePolicy = o.initFailurePolicy
+	scc.requiredSecrets = o.requiredSecrets
+	scc.protectedValues = o.protectedValues
+	scc.logScrubbing = o.logScrubbing
+	scc.legacyBinaryValue = o.legacyBinaryValue
+	for _, secretListener := range o.secretListeners {
+		scc.AddSecretChangeListener(secretListener.secretName, secretListener.listener)
+	}
+	for _, listener := range o.globalListeners {
+		scc.AddGlobalSecretChangeListener(listener)
+	}
+	return scc
+}
+
+func (o *options) buildSecretManagerClient() service.SecretManagerClient {
+	builder := service.NewDefaultSecretManagerClientBuilder()
+	if o.credential != nil {
+		builder.WithCredential(o.credential)
+	}
+	for _, regionInfo := range o.regionInfos {
+		builder.AddRegionInfo(regionInfo)
+	}
+	for _, config := range o.configs {
+		builder.AddConfig(config)
+	}
+	if o.customConfigFile != "" {
+		builder.WithCustomConfigFile(o.customConfigFile)
+	}
+	if o.backoff != nil {
+		builder.WithBackoffStrategy(service.NewFullJitterBackoffStrategy(o.backoff.maxAttempts,
+			durationToMillis(o.backoff.initialInterval), durationToMillis(o.backoff.capacity)))
+	} else if o.backoffStrategy != nil {
+		builder.WithBackoffStrategy(o.backoffStrategy)
+	}
+	if o.metrics != nil {
+		builder.WithMetrics(o.metrics)
+	}
+	if o.tracer != nil {
+		builder.WithTracer(o.tracer)
+	}
+	return builder.Build()
+}
+
+// durationToMillis 将time.Duration转换为毫秒
+func durationToMillis(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
@@ -0,0 +1,92 @@
+package sdk
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Options(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("options_secret", "v1", "value1")
+	client, err := New(
+		WithSecretManagerClient(smc),
+		WithSecretTTL("options_secret", 90*time.Second),
+		WithStaleWhileRevalidate(time.Minute),
+		WithNegativeCacheTTL(30*time.Second),
+		WithRotationRefresh(time.Second, time.Hour),
+		WithRefreshWorkers(2),
+		WithInitFailurePolicy(InitBestEffort),
+		WithBoundedCache(100, 10*time.Minute),
+	)
+	assert.Nil(t, err)
+	defer client.Close()
+	assert.Equal(t, int64(90*1000), client.secretTTLMap["options_secret"])
+	assert.Equal(t, int64(60*1000), client.maxStaleness)
+	assert.Equal(t, int64(30*1000), client.negativeCacheTTL)
+	assert.Equal(t, 2, client.refreshWorkers)
+	assert.Equal(t, InitBestEffort, client.initFailurePolicy)
+	storeStrategy, ok := client.cacheSecretStoreStrategy.(*cache.MemoryCacheSecretStoreStrategy)
+	assert.True(t, ok)
+	assert.Equal(t, 100, storeStrategy.MaxEntries)
+	assert.Equal(t, int64(10*60*1000), storeStrategy.IdleTimeout)
+	value, err := client.GetStringValue("options_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", value)
+}
+
+func TestNew_AggregatedOptionsError(t *testing.T) {
+	cacheSecretPath, err := ioutil.TempDir("", "secrets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(cacheSecretPath)
+
+	client, err := New(
+		WithSecretManagerClient(newMockSecretManagerClient()),
+		WithRegion("cn-hangzhou"),
+		WithSecretTTL("options_secret", 500*time.Microsecond),
+		WithPreloadConcurrency(0),
+		WithRefreshJitter(0.8, 0.1),
+		WithRotationRefresh(time.Second, time.Hour),
+		WithBackoff(3, time.Second, 10*time.Millisecond),
+		WithBackoffStrategy(service.NewFullJitterBackoffStrategy(3, 1000, 10000)),
+		WithProtectedValues(),
+		WithCacheSecretStrategy(cache.NewFileCacheSecretStoreStrategy(cacheSecretPath, true, "1234abcd")),
+		WithCacheStage(""),
+		WithBoundedCache(-1, time.Microsecond),
+		nil,
+	)
+	assert.Nil(t, client)
+	var optionsErr *OptionsError
+	assert.True(t, errors.As(err, &optionsErr))
+	assert.Equal(t, 11, len(optionsErr.Errs))
+	for _, message := range []string{"ttl[500µs]", "preloadConcurrency[0]", "backoff capacity", "stage must not be empty",
+		"WithSecretManagerClient", "WithBackoffStrategy", "WithRotationRefresh", "FileCacheSecretStoreStrategy",
+		"maxEntries[-1]", "idleTimeout[1µs]", "WithBoundedCache"} {
+		assert.Contains(t, err.Error(), message)
+	}
+}
+
+type initFailedStoreStrategy struct {
+	cache.SecretCacheStoreStrategy
+}
+
+func (s *initFailedStoreStrategy) Init() error {
+	return errors.New("store init failed")
+}
+
+func TestNew_CloseOnInitError(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	client, err := New(
+		WithSecretManagerClient(smc),
+		WithCacheSecretStrategy(&initFailedStoreStrategy{SecretCacheStoreStrategy: cache.NewMemoryCacheSecretStoreStrategy()}),
+	)
+	assert.Nil(t, client)
+	assert.Equal(t, "store init failed", err.Error())
+	assert.True(t, smc.isClosed())
+}
@@ -0,0 +1,84 @@
+package protected
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrBufferDestroyed 受保护内存已被清零释放
+var ErrBufferDestroyed = errors.New("protected buffer has been destroyed")
+
+// Buffer 受保护的凭据值内存，尽可能通过mlock锁定避免被换出到磁盘，销毁时清零
+type Buffer struct {
+	mtx       sync.RWMutex
+	data      []byte
+	locked    bool
+	destroyed bool
+}
+
+// NewBuffer 复制data到新分配的受保护内存，调用方可在之后自行清零data
+func NewBuffer(data []byte) *Buffer {
+	b := &Buffer{data: allocate(len(data))}
+	copy(b.data, data)
+	if len(b.data) > 0 {
+		b.locked = lockMemory(b.data[:cap(b.data)]) == nil
+	}
+	return b
+}
+
+// Use 在回调中访问凭据值，回调返回后不得继续持有或修改value
+func (b *Buffer) Use(fn func(value []byte) error) error {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	if b.destroyed {
+		return ErrBufferDestroyed
+	}
+	return fn(b.data)
+}
+
+// Copy 返回凭据值的副本，调用方使用完毕后应调用Wipe清零
+func (b *Buffer) Copy() ([]byte, error) {
+	var value []byte
+	err := b.Use(func(data []byte) error {
+		value = append(make([]byte, 0, len(data)), data...)
+		return nil
+	})
+	return value, err
+}
+
+// Len 返回凭据值长度
+func (b *Buffer) Len() int {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	return len(b.data)
+}
+
+// Locked 返回内存是否已被mlock锁定
+func (b *Buffer) Locked() bool {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	return b.locked
+}
+
+// Destroy 清零并解锁内存，重复调用无副作用
+func (b *Buffer) Destroy() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.destroyed {
+		return
+	}
+	Wipe(b.data)
+	if b.locked {
+		_ = unlockMemory(b.data[:cap(b.data)])
+		b.locked = false
+	}
+	b.data = nil
+	b.destroyed = true
+}
+
+// Wipe 将字节切片清零
+func Wipe(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
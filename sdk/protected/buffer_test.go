@@ -0,0 +1,33 @@
+package protected
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	source := []byte("secret-value")
+	buffer := NewBuffer(source)
+	Wipe(source)
+	assert.Equal(t, make([]byte, len(source)), source)
+	assert.Equal(t, len(source), buffer.Len())
+
+	value, err := buffer.Copy()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("secret-value"), value)
+
+	var retained []byte
+	err = buffer.Use(func(value []byte) error {
+		retained = value
+		return nil
+	})
+	assert.Nil(t, err)
+
+	buffer.Destroy()
+	buffer.Destroy()
+	assert.Equal(t, make([]byte, len("secret-value")), retained)
+	assert.False(t, buffer.Locked())
+	_, err = buffer.Copy()
+	assert.Equal(t, ErrBufferDestroyed, err)
+}
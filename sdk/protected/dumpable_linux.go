@@ -0,0 +1,11 @@
+package protected
+
+import "syscall"
+
+// DisableCoreDump 将进程标记为不可dump，避免凭据值出现在core dump中
+func DisableCoreDump() error {
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_SET_DUMPABLE, 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
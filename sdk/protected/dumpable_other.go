@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package protected
+
+// DisableCoreDump 非Linux平台不做处理
+func DisableCoreDump() error {
+	return nil
+}
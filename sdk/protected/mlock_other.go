@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package protected
+
+import "errors"
+
+func allocate(size int) []byte {
+	return make([]byte, size)
+}
+
+func lockMemory(data []byte) error {
+	return errors.New("mlock is not supported on this platform")
+}
+
+func unlockMemory(data []byte) error {
+	return nil
+}
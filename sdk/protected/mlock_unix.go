@@ -0,0 +1,31 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package protected
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// allocate 分配按页对齐且独占整页的内存，cap为页大小的整数倍，
+// mlock及munlock以页为单位且不计数，独占整页可避免解锁时影响其他Buffer或堆对象
+func allocate(size int) []byte {
+	if size == 0 {
+		return []byte{}
+	}
+	pageSize := os.Getpagesize()
+	length := (size + pageSize - 1) / pageSize * pageSize
+	mem := make([]byte, length+pageSize)
+	offset := (pageSize - int(uintptr(unsafe.Pointer(&mem[0]))%uintptr(pageSize))) % pageSize
+	return mem[offset : offset+size : offset+length]
+}
+
+func lockMemory(data []byte) error {
+	return syscall.Mlock(data)
+}
+
+func unlockMemory(data []byte) error {
+	return syscall.Munlock(data)
+}
@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package protected
+
+import (
+	"os"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer_PageAligned(t *testing.T) {
+	pageSize := uintptr(os.Getpagesize())
+	buffers := []*Buffer{NewBuffer([]byte("a")), NewBuffer([]byte("b")), NewBuffer(make([]byte, pageSize+1))}
+	for _, buffer := range buffers {
+		start := uintptr(unsafe.Pointer(&buffer.data[0]))
+		assert.Equal(t, uintptr(0), start%pageSize)
+		assert.Equal(t, 0, cap(buffer.data)%int(pageSize))
+		assert.True(t, cap(buffer.data) >= len(buffer.data))
+	}
+	assert.Equal(t, int(pageSize)*2, cap(buffers[2].data))
+
+	// 销毁一个Buffer不影响其他Buffer
+	buffers[0].Destroy()
+	value, err := buffers[1].Copy()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("b"), value)
+	buffers[1].Destroy()
+	buffers[2].Destroy()
+}
@@ -0,0 +1,140 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+	"github.com/aliyun/credentials-go/credentials"
+)
+
+const (
+	// ramSecretCredentialType 凭证类型，与credentials-go的AccessKey凭证一致
+	ramSecretCredentialType = "access_key"
+	// ramSecretCredentialProviderName 凭证提供者名称
+	ramSecretCredentialProviderName = "secrets_manager_ram_credentials"
+)
+
+var _ credentials.Credential = (*RAMSecretCredential)(nil)
+
+// RAMSecretCredential 基于RAM托管凭据实现credentials.Credential，可直接用于配置其他阿里云SDK客户端
+// 每次调用从缓存客户端获取当前版本的AccessKey，凭据轮转后自动使用新的AccessKey
+type RAMSecretCredential struct {
+	client     *SecretManagerCacheClient
+	secretName string
+	// 凭据轮转后继续使用上一版本(ACSPrevious)AccessKey的时长，单位MS，用于等待新AccessKey生效
+	gracePeriod int64
+
+	mtx              sync.Mutex
+	currentVersionId string
+	// 当前版本的轮转时间戳，单位MS，未知时为0
+	rotatedTimestamp int64
+}
+
+// NewRAMSecretCredential 根据RAM托管凭据构建credentials.Credential
+// gracePeriod 为轮转后继续使用上一版本AccessKey的时长，单位MS，小于等于0表示轮转后立即使用新AccessKey
+func NewRAMSecretCredential(client *SecretManagerCacheClient, secretName string, gracePeriod int64) (*RAMSecretCredential, error) {
+	if client == nil {
+		return nil, errors.New(fmt.Sprintf("the argument client must not be nil"))
+	}
+	if secretName == "" {
+		return nil, errors.New(fmt.Sprintf("the argument secretName must not be empty"))
+	}
+	rsc := &RAMSecretCredential{
+		client:      client,
+		secretName:  secretName,
+		gracePeriod: gracePeriod,
+	}
+	if _, err := rsc.getRAMCredentials(); err != nil {
+		return nil, err
+	}
+	return rsc, nil
+}
+
+// Deprecated: 请使用GetCredential
+func (rsc *RAMSecretCredential) GetAccessKeyId() (*string, error) {
+	ramCredentials, err := rsc.getRAMCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return tea.String(ramCredentials.AccessKeyId), nil
+}
+
+// Deprecated: 请使用GetCredential
+func (rsc *RAMSecretCredential) GetAccessKeySecret() (*string, error) {
+	ramCredentials, err := rsc.getRAMCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return tea.String(ramCredentials.AccessKeySecret), nil
+}
+
+// Deprecated: 请使用GetCredential
+func (rsc *RAMSecretCredential) GetSecurityToken() (*string, error) {
+	return tea.String(""), nil
+}
+
+func (rsc *RAMSecretCredential) GetBearerToken() *string {
+	return tea.String("")
+}
+
+func (rsc *RAMSecretCredential) GetType() *string {
+	return tea.String(ramSecretCredentialType)
+}
+
+func (rsc *RAMSecretCredential) GetCredential() (*credentials.CredentialModel, error) {
+	ramCredentials, err := rsc.getRAMCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return &credentials.CredentialModel{
+		AccessKeyId:     tea.String(ramCredentials.AccessKeyId),
+		AccessKeySecret: tea.String(ramCredentials.AccessKeySecret),
+		SecurityToken:   tea.String(""),
+		Type:            tea.String(ramSecretCredentialType),
+		ProviderName:    tea.String(ramSecretCredentialProviderName),
+	}, nil
+}
+
+// getRAMCredentials 获取当前应使用的AccessKey，轮转后的gracePeriod内返回上一版本(ACSPrevious)的AccessKey
+func (rsc *RAMSecretCredential) getRAMCredentials() (*models.RAMCredentials, error) {
+	secretInfo, ramCredentials, err := rsc.client.getRAMCredentials(context.Background(), rsc.secretName, rsc.client.stage)
+	if err != nil {
+		return nil, err
+	}
+	if rsc.gracePeriod <= 0 || !rsc.inGracePeriod(secretInfo) {
+		return ramCredentials, nil
+	}
+	_, previousCredentials, err := rsc.client.getRAMCredentials(context.Background(), rsc.secretName, utils.StageAcsPrevious)
+	if err != nil {
+		// 凭据的首个版本没有ACSPrevious
+		logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s, get %s credentials failed, use current credentials, %v", rsc.secretName, utils.StageAcsPrevious, err)
+		return ramCredentials, nil
+	}
+	return previousCredentials, nil
+}
+
+// inGracePeriod 判断当前版本是否处于轮转后的gracePeriod内
+// 轮转时间为当前版本的创建时间，创建时间无法解析时为本进程检测到VersionId变化的时间
+func (rsc *RAMSecretCredential) inGracePeriod(secretInfo *models.SecretInfo) bool {
+	now := rsc.client.nowMillis()
+	rsc.mtx.Lock()
+	defer rsc.mtx.Unlock()
+	if rsc.currentVersionId != secretInfo.VersionId {
+		rotatedTimestamp := int64(0)
+		if createTime, err := time.Parse(time.RFC3339, secretInfo.CreateTime); err == nil {
+			rotatedTimestamp = createTime.UnixNano() / 1e6
+		} else if rsc.currentVersionId != "" {
+			rotatedTimestamp = now
+		}
+		rsc.currentVersionId = secretInfo.VersionId
+		rsc.rotatedTimestamp = rotatedTimestamp
+	}
+	return rsc.rotatedTimestamp > 0 && now-rsc.rotatedTimestamp < rsc.gracePeriod
+}
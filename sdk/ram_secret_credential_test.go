@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func putRAMSecret(smc *mockSecretManagerClient, secretName, stage, versionId, accessKeyId string, createTime time.Time) {
+	smc.putStageSecret(secretName, stage, versionId, `{"AccessKeyId":"`+accessKeyId+`","AccessKeySecret":"`+accessKeyId+`_secret"}`)
+	smc.setSecretType(secretName, stage, utils.SecretTypeRAMCredentials)
+	smc.mtx.Lock()
+	defer smc.mtx.Unlock()
+	smc.secrets[cache.CacheKey(secretName, stage)].CreateTime = tea.String(createTime.UTC().Format(time.RFC3339))
+}
+
+func TestRAMSecretCredential(t *testing.T) {
+	clock := newFakeClock()
+	smc := newMockSecretManagerClient()
+	putRAMSecret(smc, "ram_credential_secret", utils.StageAcsCurrent, "v1", "ak1", clock.Now().Add(-time.Hour))
+	smc.putSecret("generic_credential_secret", "v1", "value")
+	client := newMockCacheClient(smc)
+	client.clock = clock.Now
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := NewRAMSecretCredential(client, "generic_credential_secret", 0)
+	assert.NotNil(t, err)
+
+	// 首个版本没有ACSPrevious，使用当前AccessKey
+	credential, err := NewRAMSecretCredential(client, "ram_credential_secret", 60*1000)
+	assert.Nil(t, err)
+	assert.Equal(t, "access_key", tea.StringValue(credential.GetType()))
+	model, err := credential.GetCredential()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak1", tea.StringValue(model.AccessKeyId))
+	assert.Equal(t, "ak1_secret", tea.StringValue(model.AccessKeySecret))
+	accessKeyId, err := credential.GetAccessKeyId()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak1", tea.StringValue(accessKeyId))
+
+	putRAMSecret(smc, "ram_credential_secret", utils.StageAcsPrevious, "v1", "ak1", clock.Now().Add(-time.Hour))
+	putRAMSecret(smc, "ram_credential_secret", utils.StageAcsCurrent, "v2", "ak2", clock.Now())
+	_, err = client.RefreshNow("ram_credential_secret")
+	assert.Nil(t, err)
+	model, err = credential.GetCredential()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak1", tea.StringValue(model.AccessKeyId))
+
+	// 未观察到VersionId变化的实例同样在gracePeriod内使用ACSPrevious
+	fresh, err := NewRAMSecretCredential(client, "ram_credential_secret", 60*1000)
+	assert.Nil(t, err)
+	model, err = fresh.GetCredential()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak1", tea.StringValue(model.AccessKeyId))
+
+	clock.Advance(2 * time.Minute)
+	model, err = credential.GetCredential()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak2", tea.StringValue(model.AccessKeyId))
+	model, err = fresh.GetCredential()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak2", tea.StringValue(model.AccessKeyId))
+
+	noGrace, err := NewRAMSecretCredential(client, "ram_credential_secret", 0)
+	assert.Nil(t, err)
+	putRAMSecret(smc, "ram_credential_secret", utils.StageAcsPrevious, "v2", "ak2", clock.Now().Add(-2*time.Minute))
+	putRAMSecret(smc, "ram_credential_secret", utils.StageAcsCurrent, "v3", "ak3", clock.Now())
+	_, err = client.RefreshNow("ram_credential_secret")
+	assert.Nil(t, err)
+	accessKeySecret, err := noGrace.GetAccessKeySecret()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak3_secret", tea.StringValue(accessKeySecret))
+}
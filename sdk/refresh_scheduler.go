@@ -0,0 +1,245 @@
+package sdk
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ScheduledRefresh 待执行的凭据刷新任务信息
+type ScheduledRefresh struct {
+	SecretName string
+	Stage      string
+	// 计划执行时间戳，单位MS
+	ExecuteTime int64
+}
+
+type scheduledTask struct {
+	key         string
+	secretName  string
+	stage       string
+	executeTime int64
+	run         func()
+	// 在优先队列中的下标，不在队列中时为-1
+	index int
+}
+
+// refreshQueue 按执行时间排序的优先队列
+type refreshQueue []*scheduledTask
+
+func (q refreshQueue) Len() int {
+	return len(q)
+}
+
+func (q refreshQueue) Less(i, j int) bool {
+	return q[i].executeTime < q[j].executeTime
+}
+
+func (q refreshQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *refreshQueue) Push(x interface{}) {
+	task := x.(*scheduledTask)
+	task.index = len(*q)
+	*q = append(*q, task)
+}
+
+func (q *refreshQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	task := old[n-1]
+	old[n-1] = nil
+	task.index = -1
+	*q = old[:n-1]
+	return task
+}
+
+// refreshScheduler 由单个调度协程按执行时间分发刷新任务，并由固定数量的工作协程执行
+type refreshScheduler struct {
+	workers int
+	// 计算任务到期时间的时钟，为nil时使用time.Now
+	clock func() time.Time
+
+	mtx    sync.Mutex
+	queue  refreshQueue
+	tasks  map[string]*scheduledTask
+	paused bool
+
+	wakeCh    chan struct{}
+	taskCh    chan *scheduledTask
+	closeCh   chan struct{}
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+func newRefreshScheduler(workers int, clock func() time.Time) *refreshScheduler {
+	if workers <= 0 {
+		workers = defaultRefreshWorkers
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	return &refreshScheduler{
+		workers: workers,
+		clock:   clock,
+		tasks:   make(map[string]*scheduledTask),
+		wakeCh:  make(chan struct{}, 1),
+		taskCh:  make(chan *scheduledTask),
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (rs *refreshScheduler) start() {
+	rs.startOnce.Do(func() {
+		go rs.dispatch()
+		for i := 0; i < rs.workers; i++ {
+			go rs.work()
+		}
+	})
+}
+
+// schedule 添加刷新任务，key已存在时替换原任务
+func (rs *refreshScheduler) schedule(key, secretName, stage string, executeTime int64, run func()) {
+	rs.mtx.Lock()
+	if task, ok := rs.tasks[key]; ok {
+		heap.Remove(&rs.queue, task.index)
+	}
+	task := &scheduledTask{
+		key:         key,
+		secretName:  secretName,
+		stage:       stage,
+		executeTime: executeTime,
+		run:         run,
+	}
+	heap.Push(&rs.queue, task)
+	rs.tasks[key] = task
+	rs.mtx.Unlock()
+	rs.wake()
+}
+
+// cancel 取消尚未执行的刷新任务
+func (rs *refreshScheduler) cancel(key string) {
+	rs.mtx.Lock()
+	defer rs.mtx.Unlock()
+	if task, ok := rs.tasks[key]; ok {
+		heap.Remove(&rs.queue, task.index)
+		delete(rs.tasks, key)
+	}
+}
+
+func (rs *refreshScheduler) pause() {
+	rs.mtx.Lock()
+	rs.paused = true
+	rs.mtx.Unlock()
+}
+
+func (rs *refreshScheduler) resume() {
+	rs.mtx.Lock()
+	rs.paused = false
+	rs.mtx.Unlock()
+	rs.wake()
+}
+
+func (rs *refreshScheduler) isPaused() bool {
+	rs.mtx.Lock()
+	defer rs.mtx.Unlock()
+	return rs.paused
+}
+
+// upcoming 按执行时间升序返回最近的limit个待执行任务，limit小于等于0时返回全部
+func (rs *refreshScheduler) upcoming(limit int) []ScheduledRefresh {
+	rs.mtx.Lock()
+	refreshes := make([]ScheduledRefresh, 0, len(rs.queue))
+	for _, task := range rs.queue {
+		refreshes = append(refreshes, ScheduledRefresh{
+			SecretName:  task.secretName,
+			Stage:       task.stage,
+			ExecuteTime: task.executeTime,
+		})
+	}
+	rs.mtx.Unlock()
+	sort.Slice(refreshes, func(i, j int) bool {
+		return refreshes[i].ExecuteTime < refreshes[j].ExecuteTime
+	})
+	if limit > 0 && len(refreshes) > limit {
+		refreshes = refreshes[:limit]
+	}
+	return refreshes
+}
+
+func (rs *refreshScheduler) close() {
+	rs.closeOnce.Do(func() {
+		close(rs.closeCh)
+	})
+}
+
+func (rs *refreshScheduler) wake() {
+	select {
+	case rs.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch 取出到期任务交给工作协程，工作协程均繁忙时等待，从而限制并发刷新数量
+func (rs *refreshScheduler) dispatch() {
+	for {
+		task, wait := rs.next()
+		if task != nil {
+			select {
+			case rs.taskCh <- task:
+			case <-rs.closeCh:
+				return
+			}
+			continue
+		}
+		var timerCh <-chan time.Time
+		var timer *time.Timer
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timerCh = timer.C
+		}
+		select {
+		case <-rs.wakeCh:
+		case <-timerCh:
+		case <-rs.closeCh:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// next 返回已到期的任务，没有到期任务时返回距离下一个任务的等待时间，0表示等待唤醒
+func (rs *refreshScheduler) next() (*scheduledTask, time.Duration) {
+	rs.mtx.Lock()
+	defer rs.mtx.Unlock()
+	if rs.paused || len(rs.queue) == 0 {
+		return nil, 0
+	}
+	delay := rs.queue[0].executeTime - rs.clock().UnixNano()/1e6
+	if delay > 0 {
+		return nil, time.Duration(delay) * time.Millisecond
+	}
+	task := heap.Pop(&rs.queue).(*scheduledTask)
+	delete(rs.tasks, task.key)
+	return task, 0
+}
+
+func (rs *refreshScheduler) work() {
+	for {
+		select {
+		case task := <-rs.taskCh:
+			task.run()
+		case <-rs.closeCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package sdk
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func nowMillis() int64 {
+	return time.Now().UnixNano() / 1e6
+}
+
+func TestRefreshScheduler_Order(t *testing.T) {
+	rs := newRefreshScheduler(1, nil)
+	rs.start()
+	defer rs.close()
+	var mtx sync.Mutex
+	var executed []string
+	var wg sync.WaitGroup
+	now := nowMillis()
+	for _, key := range []string{"c", "a", "b"} {
+		wg.Add(1)
+		key := key
+		executeTime := now + 50
+		if key == "a" {
+			executeTime = now + 10
+		} else if key == "b" {
+			executeTime = now + 30
+		}
+		rs.schedule(key, key, "", executeTime, func() {
+			mtx.Lock()
+			executed = append(executed, key)
+			mtx.Unlock()
+			wg.Done()
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"a", "b", "c"}, executed)
+}
+
+func TestRefreshScheduler_BoundedWorkers(t *testing.T) {
+	rs := newRefreshScheduler(2, nil)
+	rs.start()
+	defer rs.close()
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	now := nowMillis()
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		key := string(rune('a' + i))
+		rs.schedule(key, key, "", now, func() {
+			defer wg.Done()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+}
+
+func TestRefreshScheduler_PauseResumeAndCancel(t *testing.T) {
+	rs := newRefreshScheduler(1, nil)
+	rs.start()
+	defer rs.close()
+	var executed int32
+	rs.pause()
+	assert.True(t, rs.isPaused())
+	now := nowMillis()
+	rs.schedule("paused", "paused", "ACSCurrent", now, func() {
+		atomic.AddInt32(&executed, 1)
+	})
+	rs.schedule("canceled", "canceled", "ACSCurrent", now+10, func() {
+		atomic.AddInt32(&executed, 1)
+	})
+	rs.schedule("later", "later", "ACSCurrent", now+60*1000, func() {})
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&executed))
+
+	upcoming := rs.upcoming(2)
+	assert.Equal(t, 2, len(upcoming))
+	assert.Equal(t, "paused", upcoming[0].SecretName)
+	assert.Equal(t, "canceled", upcoming[1].SecretName)
+
+	rs.cancel("canceled")
+	rs.resume()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&executed))
+	upcoming = rs.upcoming(0)
+	assert.Equal(t, 1, len(upcoming))
+	assert.Equal(t, "later", upcoming[0].SecretName)
+}
+
+func TestRefreshScheduler_Clock(t *testing.T) {
+	clock := newFakeClock()
+	rs := newRefreshScheduler(1, clock.Now)
+	rs.start()
+	defer rs.close()
+	done := make(chan struct{})
+	rs.schedule("clock", "clock", "ACSCurrent", clock.Now().Add(time.Hour).UnixNano()/1e6, func() {
+		close(done)
+	})
+	select {
+	case <-done:
+		t.Fatal("task executed before the clock reached its execute time")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(2 * time.Hour)
+	rs.wake()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task not executed after the clock reached its execute time")
+	}
+}
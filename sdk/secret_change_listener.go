@@ -0,0 +1,121 @@
+package sdk
+
+import (
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+)
+
+// SecretChangeListener 凭据变更监听器
+// oldSecretInfo为变更前缓存的凭据信息，newSecretInfo为刷新后的凭据信息
+type SecretChangeListener func(oldSecretInfo, newSecretInfo *models.SecretInfo)
+
+// AddSecretChangeListener 注册指定凭据名称的变更监听器，凭据VersionId或凭据值发生变化时回调
+func (scc *SecretManagerCacheClient) AddSecretChangeListener(secretName string, listener SecretChangeListener) {
+	if listener == nil {
+		return
+	}
+	scc.listenerMtx.Lock()
+	defer scc.listenerMtx.Unlock()
+	if scc.secretListenerMap == nil {
+		scc.secretListenerMap = make(map[string][]SecretChangeListener)
+	}
+	scc.secretListenerMap[secretName] = append(scc.secretListenerMap[secretName], listener)
+}
+
+// AddGlobalSecretChangeListener 注册所有凭据的变更监听器
+func (scc *SecretManagerCacheClient) AddGlobalSecretChangeListener(listener SecretChangeListener) {
+	if listener == nil {
+		return
+	}
+	scc.listenerMtx.Lock()
+	defer scc.listenerMtx.Unlock()
+	scc.globalListeners = append(scc.globalListeners, listener)
+}
+
+func (scc *SecretManagerCacheClient) getSecretChangeListeners(secretName string) []SecretChangeListener {
+	scc.listenerMtx.RLock()
+	defer scc.listenerMtx.RUnlock()
+	listeners := make([]SecretChangeListener, 0, len(scc.globalListeners)+len(scc.secretListenerMap[secretName]))
+	listeners = append(listeners, scc.secretListenerMap[secretName]...)
+	return append(listeners, scc.globalListeners...)
+}
+
+// secretChangeQueue 单个凭据待回调的变更通知，保证同一凭据的变更按刷新顺序回调
+type secretChangeQueue struct {
+	pending []func()
+}
+
+// notifySecretChange 凭据发生变化时异步回调监听器，不占用凭据刷新锁，release不为nil时在所有监听器回调完成后执行
+// 同一凭据的变更通知按提交顺序依次回调，不同凭据之间互不阻塞
+func (scc *SecretManagerCacheClient) notifySecretChange(oldSecretInfo, newSecretInfo *models.SecretInfo, release func()) {
+	if release == nil {
+		release = func() {}
+	}
+	if oldSecretInfo == nil || newSecretInfo == nil || !isSecretChanged(oldSecretInfo, newSecretInfo) {
+		release()
+		return
+	}
+	listeners := scc.getSecretChangeListeners(newSecretInfo.SecretName)
+	if len(listeners) == 0 {
+		release()
+		return
+	}
+	scc.enqueueSecretChange(newSecretInfo.SecretName, func() {
+		defer release()
+		for _, listener := range listeners {
+			callSecretChangeListener(listener, oldSecretInfo, newSecretInfo)
+		}
+	})
+}
+
+// enqueueSecretChange 将变更通知加入凭据队列，队列空闲时启动协程依次执行
+func (scc *SecretManagerCacheClient) enqueueSecretChange(secretName string, notify func()) {
+	scc.notifyMtx.Lock()
+	defer scc.notifyMtx.Unlock()
+	if scc.notifyQueueMap == nil {
+		scc.notifyQueueMap = make(map[string]*secretChangeQueue)
+	}
+	if queue, ok := scc.notifyQueueMap[secretName]; ok {
+		queue.pending = append(queue.pending, notify)
+		return
+	}
+	scc.notifyQueueMap[secretName] = &secretChangeQueue{}
+	go scc.drainSecretChange(secretName, notify)
+}
+
+func (scc *SecretManagerCacheClient) drainSecretChange(secretName string, notify func()) {
+	for notify != nil {
+		notify()
+		scc.notifyMtx.Lock()
+		queue := scc.notifyQueueMap[secretName]
+		if len(queue.pending) == 0 {
+			delete(scc.notifyQueueMap, secretName)
+			notify = nil
+		} else {
+			notify = queue.pending[0]
+			queue.pending[0] = nil
+			queue.pending = queue.pending[1:]
+		}
+		scc.notifyMtx.Unlock()
+	}
+}
+
+func callSecretChangeListener(listener SecretChangeListener, oldSecretInfo, newSecretInfo *models.SecretInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.GetCommonLogger(utils.ModeName).Errorf("action:secretChangeListener, secretName:%s, panic:%v", newSecretInfo.SecretName, r)
+		}
+	}()
+	listener(oldSecretInfo, newSecretInfo)
+}
+
+func isSecretChanged(oldSecretInfo, newSecretInfo *models.SecretInfo) bool {
+	if oldSecretInfo.ProtectedValue != nil || newSecretInfo.ProtectedValue != nil {
+		return oldSecretInfo.VersionId != newSecretInfo.VersionId ||
+			!isProtectedValueEqual(oldSecretInfo.ProtectedValue, newSecretInfo.ProtectedValue)
+	}
+	return oldSecretInfo.VersionId != newSecretInfo.VersionId ||
+		oldSecretInfo.SecretValue != newSecretInfo.SecretValue ||
+		string(oldSecretInfo.SecretValueByteBuffer) != string(newSecretInfo.SecretValueByteBuffer)
+}
@@ -0,0 +1,87 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretCacheClient_SecretChangeListener(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("listener_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	client.secretTTLMap["listener_secret"] = 60 * 1000
+
+	changed := make(chan [2]*models.SecretInfo, 2)
+	client.AddSecretChangeListener("listener_secret", func(oldSecretInfo, newSecretInfo *models.SecretInfo) {
+		panic("listener panic must not break refresh")
+	})
+	client.AddGlobalSecretChangeListener(func(oldSecretInfo, newSecretInfo *models.SecretInfo) {
+		changed <- [2]*models.SecretInfo{oldSecretInfo, newSecretInfo}
+	})
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	ok, err := client.RefreshNow("listener_secret")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	select {
+	case <-changed:
+		t.Fatal("listener must not be called when secret is unchanged")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	smc.putSecret("listener_secret", "v2", "value2")
+	ok, err = client.RefreshNow("listener_secret")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	select {
+	case infos := <-changed:
+		assert.Equal(t, "v1", infos[0].VersionId)
+		assert.Equal(t, "value1", infos[0].SecretValue)
+		assert.Equal(t, "v2", infos[1].VersionId)
+		assert.Equal(t, "value2", infos[1].SecretValue)
+	case <-time.After(time.Second):
+		t.Fatal("listener was not called after secret change")
+	}
+
+	value, err := client.GetStringValue("listener_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value2", value)
+}
+
+func TestSecretCacheClient_SecretChangeListenerOrder(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("order_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	client.secretTTLMap["order_secret"] = 60 * 1000
+
+	block := make(chan struct{})
+	versions := make(chan string, 4)
+	client.AddSecretChangeListener("order_secret", func(oldSecretInfo, newSecretInfo *models.SecretInfo) {
+		if newSecretInfo.VersionId == "v2" {
+			<-block
+		}
+		versions <- newSecretInfo.VersionId
+	})
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	for _, version := range []string{"v2", "v3", "v4"} {
+		smc.putSecret("order_secret", version, "value-"+version)
+		ok, err := client.RefreshNow("order_secret")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+	close(block)
+	for _, expected := range []string{"v2", "v3", "v4"} {
+		select {
+		case version := <-versions:
+			assert.Equal(t, expected, version)
+		case <-time.After(time.Second):
+			t.Fatalf("listener was not called for %s", expected)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package sdk
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+)
+
+// InitFailurePolicy 初始化时预加载凭据失败的处理策略，Init返回预加载错误时客户端已关闭
+type InitFailurePolicy int
+
+const (
+	// InitFailFast 任一凭据预加载失败时Init返回该错误，默认策略
+	InitFailFast InitFailurePolicy = iota
+	// InitRequireListed 仅WithRequiredSecrets指定的凭据预加载失败时Init返回PreloadSecretsError，其余失败的凭据在后台重试
+	InitRequireListed
+	// InitBestEffort 预加载失败时Init不返回错误，失败的凭据在后台重试
+	InitBestEffort
+)
+
+// maxPreloadRetryInterval 后台重试预加载失败凭据的最大间隔，单位MS
+const maxPreloadRetryInterval int64 = 5 * 60 * 1000
+
+// InitReport 初始化时凭据预加载结果，后台重试成功的凭据会从Failed移至Loaded，
+// 凭据不存在或无权限的凭据不再重试，保留在Failed中
+type InitReport struct {
+	Loaded []string
+	Failed map[string]error
+}
+
+type preloadRetryTask struct {
+	client     *SecretManagerCacheClient
+	secretName string
+	attempts   int
+	// 本次重试的执行时间戳，单位MS
+	executeTime int64
+}
+
+// InitReport 获取初始化时凭据预加载结果
+func (scc *SecretManagerCacheClient) InitReport() *InitReport {
+	scc.initReportMtx.Lock()
+	defer scc.initReportMtx.Unlock()
+	report := &InitReport{
+		Loaded: make([]string, 0, len(scc.initLoaded)),
+		Failed: make(map[string]error, len(scc.initFailed)),
+	}
+	for secretName := range scc.initLoaded {
+		report.Loaded = append(report.Loaded, secretName)
+	}
+	sort.Strings(report.Loaded)
+	for secretName, err := range scc.initFailed {
+		report.Failed[secretName] = err
+	}
+	return report
+}
+
+// preloadSecrets 预加载凭据并按初始化失败策略处理失败的凭据
+func (scc *SecretManagerCacheClient) preloadSecrets() error {
+	secretNames := scc.getPreloadSecretNames()
+	errs := scc.runConcurrently(secretNames, func(index int, secretName string) error {
+		return scc.preloadSecret(context.Background(), secretName)
+	})
+	required := make(map[string]bool, len(scc.requiredSecrets))
+	for _, secretName := range scc.requiredSecrets {
+		required[secretName] = true
+	}
+	var firstErr error
+	requiredFailed := make(map[string]error)
+	for i, secretName := range secretNames {
+		scc.recordPreloadResult(secretName, errs[i])
+		if errs[i] == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = errs[i]
+		}
+		if required[secretName] {
+			requiredFailed[secretName] = errs[i]
+		}
+	}
+	switch scc.initFailurePolicy {
+	case InitRequireListed:
+		if len(requiredFailed) > 0 {
+			return &PreloadSecretsError{Failed: requiredFailed}
+		}
+	case InitBestEffort:
+	default:
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+	for i, secretName := range secretNames {
+		if errs[i] != nil && !utils.JudgeNegativeCacheException(errs[i]) {
+			scc.addPreloadRetryTask(&preloadRetryTask{client: scc, secretName: secretName})
+		}
+	}
+	return nil
+}
+
+// getPreloadSecretNames 获取需要预加载的凭据，包括WithSecretTTL指定的凭据及必须加载的凭据
+func (scc *SecretManagerCacheClient) getPreloadSecretNames() []string {
+	secretNames := scc.ListSecrets()
+	for _, secretName := range scc.requiredSecrets {
+		if _, ok := scc.getSecretTTL(secretName); !ok {
+			secretNames = append(secretNames, secretName)
+		}
+	}
+	return secretNames
+}
+
+// preloadSecret 获取凭据并开启定时刷新
+func (scc *SecretManagerCacheClient) preloadSecret(ctx context.Context, secretName string) error {
+	secretInfo, err := scc.getSecretValue(ctx, secretName, scc.stage)
+	if err != nil {
+		logger.GetCommonLogger(utils.ModeName).Errorf("action:preloadSecret, secretName:%s, %+v", secretName, err)
+		return err
+	}
+	return scc.storeAndRefresh(ctx, secretName, scc.stage, secretInfo)
+}
+
+func (scc *SecretManagerCacheClient) recordPreloadResult(secretName string, err error) {
+	scc.initReportMtx.Lock()
+	defer scc.initReportMtx.Unlock()
+	if scc.initLoaded == nil {
+		scc.initLoaded = make(map[string]struct{})
+	}
+	if scc.initFailed == nil {
+		scc.initFailed = make(map[string]error)
+	}
+	if err == nil {
+		scc.initLoaded[secretName] = struct{}{}
+		delete(scc.initFailed, secretName)
+	} else {
+		scc.initFailed[secretName] = err
+	}
+}
+
+// addPreloadRetryTask 按指数退避添加预加载失败凭据的后台重试任务
+func (scc *SecretManagerCacheClient) addPreloadRetryTask(task *preloadRetryTask) {
+	if scc.isClosed() || scc.scheduler == nil {
+		return
+	}
+	interval := int64(utils.DefaultRetryInitialIntervalMills)
+	for i := 0; i < task.attempts && interval < maxPreloadRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxPreloadRetryInterval {
+		interval = maxPreloadRetryInterval
+	}
+	task.executeTime = scc.nowMillis() + interval
+	key := cache.CacheKey(task.secretName, scc.stage)
+	scc.scheduledMap.Set(key, task.executeTime)
+	scc.scheduler.schedule(key, task.secretName, scc.stage, task.executeTime, task.getRunnable())
+}
+
+func (task *preloadRetryTask) getRunnable() func() {
+	return func() {
+		scc := task.client
+		if !scc.beginRefresh() {
+			return
+		}
+		defer scc.refreshWg.Done()
+		// 凭据已被访问加载时由定时刷新任务接管
+		if _, err := scc.peekCacheSecretInfo(task.secretName, scc.stage); err == nil {
+			scc.recordPreloadResult(task.secretName, nil)
+			return
+		}
+		err := scc.preloadSecret(scc.getBackgroundContext(), task.secretName)
+		scc.recordPreloadResult(task.secretName, err)
+		if err == nil {
+			return
+		}
+		if utils.JudgeNegativeCacheException(err) {
+			// 凭据不存在或无权限时重试无意义，停止重试
+			scc.scheduledMap.RemoveCb(cache.CacheKey(task.secretName, scc.stage), func(key string, v interface{}, exists bool) bool {
+				return exists && v == task.executeTime
+			})
+			return
+		}
+		task.attempts++
+		scc.addPreloadRetryTask(task)
+	}
+}
@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretCacheClient_InitFailFast(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("preload_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	client.secretTTLMap["preload_secret"] = 60 * 1000
+	client.secretTTLMap["missing_preload_secret"] = 60 * 1000
+	assert.NotNil(t, client.Init())
+	defer client.Close()
+
+	report := client.InitReport()
+	assert.Equal(t, []string{"preload_secret"}, report.Loaded)
+	assert.NotNil(t, report.Failed["missing_preload_secret"])
+	assert.False(t, client.scheduledMap.Has("missing_preload_secret"))
+	// 初始化失败时释放已加载凭据的定时刷新任务及KMS客户端
+	assert.True(t, client.isClosed())
+	assert.False(t, client.scheduledMap.Has("preload_secret"))
+	assert.True(t, smc.isClosed())
+}
+
+func TestSecretCacheClient_InitRequireListed(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("required_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	client.initFailurePolicy = InitRequireListed
+	client.requiredSecrets = []string{"required_secret"}
+	client.secretTTLMap["optional_secret"] = 60 * 1000
+	client.secretTTLMap["missing_optional_secret"] = 60 * 1000
+	smc.putError("optional_secret", errors.New("kms unreachable"))
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	report := client.InitReport()
+	assert.Equal(t, []string{"required_secret"}, report.Loaded)
+	assert.Equal(t, 2, len(report.Failed))
+	assert.True(t, client.scheduledMap.Has("optional_secret"))
+	// 凭据不存在时不再重试
+	assert.False(t, client.scheduledMap.Has("missing_optional_secret"))
+
+	missing := newMockCacheClient(newMockSecretManagerClient())
+	missing.initFailurePolicy = InitRequireListed
+	missing.requiredSecrets = []string{"required_secret"}
+	err := missing.Init()
+	defer missing.Close()
+	assert.True(t, missing.isClosed())
+	var preloadErr *PreloadSecretsError
+	assert.True(t, errors.As(err, &preloadErr))
+	assert.Equal(t, 1, len(preloadErr.Failed))
+	assert.Contains(t, err.Error(), "required_secret")
+}
+
+func TestSecretCacheClient_InitBestEffort(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	client := newMockCacheClient(smc)
+	client.initFailurePolicy = InitBestEffort
+	client.secretTTLMap["retry_secret"] = 60 * 1000
+	smc.putError("retry_secret", errors.New("kms unreachable"))
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	report := client.InitReport()
+	assert.Equal(t, 0, len(report.Loaded))
+	assert.NotNil(t, report.Failed["retry_secret"])
+	upcoming := client.UpcomingRefreshes(0)
+	assert.Equal(t, 1, len(upcoming))
+	assert.Equal(t, "retry_secret", upcoming[0].SecretName)
+
+	smc.removeError("retry_secret")
+	smc.putSecret("retry_secret", "v1", "value1")
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && len(client.InitReport().Loaded) == 0 {
+		time.Sleep(100 * time.Millisecond)
+	}
+	report = client.InitReport()
+	assert.Equal(t, []string{"retry_secret"}, report.Loaded)
+	assert.Equal(t, 0, len(report.Failed))
+	cacheSecretInfo, err := client.cacheSecretStoreStrategy.GetCacheSecretInfo("retry_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", cacheSecretInfo.SecretInfo.SecretValue)
+	assert.True(t, client.scheduledMap.Has("retry_secret"))
+}
+
+func TestSecretCacheClient_InitRetryStopsOnNotFound(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	client := newMockCacheClient(smc)
+	client.initFailurePolicy = InitBestEffort
+	client.secretTTLMap["deleted_secret"] = 60 * 1000
+	smc.putError("deleted_secret", errors.New("kms unreachable"))
+	assert.Nil(t, client.Init())
+	defer client.Close()
+	key := cache.CacheKey("deleted_secret", utils.StageAcsCurrent)
+	assert.True(t, client.scheduledMap.Has(key))
+
+	// 重试时凭据已被删除，停止重试并保留失败结果
+	smc.removeError("deleted_secret")
+	client.scheduler.mtx.Lock()
+	run := client.scheduler.tasks[key].run
+	client.scheduler.mtx.Unlock()
+	client.scheduler.cancel(key)
+	run()
+
+	assert.False(t, client.scheduledMap.Has(key))
+	assert.Equal(t, 0, len(client.UpcomingRefreshes(0)))
+	report := client.InitReport()
+	assert.Equal(t, 0, len(report.Loaded))
+	assert.True(t, utils.JudgeNegativeCacheException(report.Failed["deleted_secret"]))
+}
@@ -0,0 +1,206 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+)
+
+// parsedSecretValue 按凭据VersionId缓存的JSON解析结果
+type parsedSecretValue struct {
+	versionId string
+
+	once     sync.Once
+	value    interface{}
+	parseErr error
+
+	// 按目标类型缓存的解析结果，key为reflect.Type，value为reflect.Value，返回给调用方前深拷贝
+	typedValues sync.Map
+}
+
+// GetJSONValue 将凭据JSON值解析到out，out必须为非nil指针
+// 同一凭据版本的解析结果按out类型缓存，out为缓存的深拷贝，修改不影响缓存
+func (scc *SecretManagerCacheClient) GetJSONValue(secretName string, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New(fmt.Sprintf("the argument out must be a non-nil pointer"))
+	}
+	secretInfo, parsed, err := scc.getParsedSecretValue(secretName, scc.stage)
+	if err != nil {
+		return err
+	}
+	return decodeJSONValue(secretInfo, parsed, rv)
+}
+
+// decodeJSONValue 将凭据JSON值解析到rv指向的对象并按类型缓存解析结果，解析失败时不修改rv指向的对象
+func decodeJSONValue(secretInfo *models.SecretInfo, parsed *parsedSecretValue, rv reflect.Value) error {
+	typ := rv.Type().Elem()
+	if v, ok := parsed.typedValues.Load(typ); ok {
+		rv.Elem().Set(copyReflectValue(v.(reflect.Value)))
+		return nil
+	}
+	typedValue := reflect.New(typ)
+	if err := json.Unmarshal([]byte(secretInfo.SecretValue), typedValue.Interface()); err != nil {
+		return &SecretNotJSONError{SecretName: secretInfo.SecretName, Err: err}
+	}
+	parsed.typedValues.Store(typ, typedValue.Elem())
+	rv.Elem().Set(copyReflectValue(typedValue.Elem()))
+	return nil
+}
+
+// copyReflectValue 深拷贝解析结果中的指针、map、slice及数组，结构体的未导出字段为浅拷贝
+func copyReflectValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(copyReflectValue(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(copyReflectValue(v.Elem()))
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), copyReflectValue(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(copyReflectValue(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(copyReflectValue(v.Index(i)))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(copyReflectValue(v.Field(i)))
+			}
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// GetSecretField 获取凭据JSON值中指定路径的字段，路径以"."分隔，数组元素使用下标，如"db.password"、"hosts.0"
+// 返回的map、slice为缓存的副本，修改不影响缓存
+func (scc *SecretManagerCacheClient) GetSecretField(secretName, fieldPath string) (interface{}, error) {
+	if fieldPath == "" {
+		return nil, errors.New(fmt.Sprintf("the argument fieldPath must not be empty"))
+	}
+	_, parsed, err := scc.getParsedSecretValue(secretName, scc.stage)
+	if err != nil {
+		return nil, err
+	}
+	current := parsed.value
+	for _, field := range strings.Split(fieldPath, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			v, ok := node[field]
+			if !ok {
+				return nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: fieldPath}
+			}
+			current = v
+		case []interface{}:
+			index, err := strconv.Atoi(field)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: fieldPath}
+			}
+			current = node[index]
+		default:
+			return nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: fieldPath}
+		}
+	}
+	return copyJSONValue(current), nil
+}
+
+// copyJSONValue 深拷贝JSON解析结果中的map和slice
+func copyJSONValue(value interface{}) interface{} {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(node))
+		for k, v := range node {
+			copied[k] = copyJSONValue(v)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(node))
+		for i, v := range node {
+			copied[i] = copyJSONValue(v)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+func (scc *SecretManagerCacheClient) getParsedSecretValue(secretName, stage string) (*models.SecretInfo, *parsedSecretValue, error) {
+	secretInfo, err := scc.getSecretInfo(context.Background(), secretName, stage)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scc.parseSecretValue(secretName, stage, secretInfo)
+}
+
+func (scc *SecretManagerCacheClient) parseSecretValue(secretName, stage string, secretInfo *models.SecretInfo) (*models.SecretInfo, *parsedSecretValue, error) {
+	if utils.TextDataType != secretInfo.SecretDataType {
+		return nil, nil, errors.New(fmt.Sprintf("the secret named[%s] do not support text value", secretName))
+	}
+	parsed := scc.loadParsedSecretValue(secretName, stage, secretInfo)
+	parsed.once.Do(func() {
+		if err := json.Unmarshal([]byte(secretInfo.SecretValue), &parsed.value); err != nil {
+			parsed.parseErr = &SecretNotJSONError{SecretName: secretName, Err: err}
+		}
+	})
+	if parsed.parseErr != nil {
+		return nil, nil, parsed.parseErr
+	}
+	return secretInfo, parsed, nil
+}
+
+func (scc *SecretManagerCacheClient) loadParsedSecretValue(secretName, stage string, secretInfo *models.SecretInfo) *parsedSecretValue {
+	// 开启受保护凭据值时不缓存解析结果，避免明文凭据值常驻内存
+	if scc.protectedValues {
+		return &parsedSecretValue{versionId: secretInfo.VersionId}
+	}
+	key := cache.CacheKey(secretName, stage)
+	if v, ok := scc.parsedValueMap.Get(key); ok {
+		if parsed, okk := v.(*parsedSecretValue); okk && parsed.versionId == secretInfo.VersionId {
+			return parsed
+		}
+	}
+	parsed := &parsedSecretValue{versionId: secretInfo.VersionId}
+	scc.parsedValueMap.Set(key, parsed)
+	return parsed
+}
@@ -0,0 +1,146 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretCacheClient_GetJSONValue(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("json_secret", "v1", `{"username":"admin","password":"pwd1","db":{"hosts":["h1","h2"],"port":3306}}`)
+	smc.putSecret("text_secret", "v1", "plain text")
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	var account struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	assert.Nil(t, client.GetJSONValue("json_secret", &account))
+	assert.Equal(t, "admin", account.Username)
+	assert.Equal(t, "pwd1", account.Password)
+
+	assert.NotNil(t, client.GetJSONValue("json_secret", account))
+
+	var notJSONErr *SecretNotJSONError
+	err := client.GetJSONValue("text_secret", &account)
+	assert.True(t, errors.As(err, &notJSONErr))
+
+	smc.putSecret("json_secret", "v2", `{"username":"admin","password":"pwd2"}`)
+	_, err = client.RefreshNow("json_secret")
+	assert.Nil(t, err)
+	assert.Nil(t, client.GetJSONValue("json_secret", &account))
+	assert.Equal(t, "pwd2", account.Password)
+}
+
+func TestSecretCacheClient_GetSecretField(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("json_secret", "v1", `{"db":{"password":"pwd1","hosts":["h1","h2"],"port":3306}}`)
+	smc.putSecret("text_secret", "v1", "plain text")
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	password, err := client.GetSecretField("json_secret", "db.password")
+	assert.Nil(t, err)
+	assert.Equal(t, "pwd1", password)
+
+	host, err := client.GetSecretField("json_secret", "db.hosts.1")
+	assert.Nil(t, err)
+	assert.Equal(t, "h2", host)
+
+	port, err := client.GetSecretField("json_secret", "db.port")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(3306), port)
+
+	var fieldNotFoundErr *SecretFieldNotFoundError
+	_, err = client.GetSecretField("json_secret", "db.username")
+	assert.True(t, errors.As(err, &fieldNotFoundErr))
+	assert.Equal(t, "db.username", fieldNotFoundErr.FieldPath)
+	_, err = client.GetSecretField("json_secret", "db.hosts.5")
+	assert.True(t, errors.As(err, &fieldNotFoundErr))
+
+	var notJSONErr *SecretNotJSONError
+	_, err = client.GetSecretField("text_secret", "password")
+	assert.True(t, errors.As(err, &notJSONErr))
+}
+
+func TestSecretCacheClient_GetJSONValueNotShared(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("json_secret", "v1", `{"db":{"hosts":["h1","h2"],"options":{"ssl":"true"}}}`)
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	type dbConfig struct {
+		DB struct {
+			Hosts   []string          `json:"hosts"`
+			Options map[string]string `json:"options"`
+		} `json:"db"`
+	}
+	var config dbConfig
+	assert.Nil(t, client.GetJSONValue("json_secret", &config))
+	config.DB.Hosts[0] = "modified"
+	config.DB.Options["ssl"] = "false"
+
+	var again dbConfig
+	assert.Nil(t, client.GetJSONValue("json_secret", &again))
+	assert.Equal(t, []string{"h1", "h2"}, again.DB.Hosts)
+	assert.Equal(t, "true", again.DB.Options["ssl"])
+
+	hosts, err := client.GetSecretField("json_secret", "db.hosts")
+	assert.Nil(t, err)
+	hosts.([]interface{})[0] = "modified"
+	host, err := client.GetSecretField("json_secret", "db.hosts.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "h1", host)
+}
+
+// countingJSONValue 记录UnmarshalJSON的调用次数
+type countingJSONValue struct {
+	Hosts []string
+}
+
+var countingJSONDecodes int32
+
+func (v *countingJSONValue) UnmarshalJSON(data []byte) error {
+	atomic.AddInt32(&countingJSONDecodes, 1)
+	var value struct {
+		Hosts []string `json:"hosts"`
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	v.Hosts = value.Hosts
+	return nil
+}
+
+func TestSecretCacheClient_GetJSONValueDecodeOnce(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("json_secret", "v1", `{"hosts":["h1","h2"]}`)
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+	atomic.StoreInt32(&countingJSONDecodes, 0)
+
+	var first, second countingJSONValue
+	assert.Nil(t, client.GetJSONValue("json_secret", &first))
+	assert.Nil(t, client.GetJSONValue("json_secret", &second))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&countingJSONDecodes))
+	assert.Equal(t, []string{"h1", "h2"}, second.Hosts)
+	first.Hosts[0] = "modified"
+	assert.Equal(t, "h1", second.Hosts[0])
+
+	// 凭据版本变化后重新解析
+	smc.putSecret("json_secret", "v2", `{"hosts":["h3"]}`)
+	_, err := client.RefreshNow("json_secret")
+	assert.Nil(t, err)
+	assert.Nil(t, client.GetJSONValue("json_secret", &second))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&countingJSONDecodes))
+	assert.Equal(t, []string{"h3"}, second.Hosts)
+}
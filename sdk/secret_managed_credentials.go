@@ -0,0 +1,101 @@
+package sdk
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+)
+
+// GetRdsCredentials 获取RDS、Redis、PolarDB托管凭据的账号和口令
+func (scc *SecretManagerCacheClient) GetRdsCredentials(secretName string) (*models.RdsCredentials, error) {
+	return scc.GetRdsCredentialsWithContext(context.Background(), secretName)
+}
+
+// GetRdsCredentialsWithContext 获取RDS、Redis、PolarDB托管凭据的账号和口令，ctx用于控制缓存未命中时请求KMS的超时和取消
+func (scc *SecretManagerCacheClient) GetRdsCredentialsWithContext(ctx context.Context, secretName string) (*models.RdsCredentials, error) {
+	credentials := &models.RdsCredentials{}
+	_, err := scc.getManagedCredentials(ctx, secretName, scc.stage, credentials, utils.SecretTypeRds, utils.SecretTypeRedis, utils.SecretTypePolarDB)
+	if err != nil {
+		return nil, err
+	}
+	if credentials.AccountName == "" {
+		return nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: "AccountName"}
+	}
+	if credentials.AccountPassword == "" {
+		return nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: "AccountPassword"}
+	}
+	return credentials, nil
+}
+
+// GetRAMCredentials 获取RAM托管凭据的AccessKey
+func (scc *SecretManagerCacheClient) GetRAMCredentials(secretName string) (*models.RAMCredentials, error) {
+	return scc.GetRAMCredentialsWithContext(context.Background(), secretName)
+}
+
+// GetRAMCredentialsWithContext 获取RAM托管凭据的AccessKey，ctx用于控制缓存未命中时请求KMS的超时和取消
+func (scc *SecretManagerCacheClient) GetRAMCredentialsWithContext(ctx context.Context, secretName string) (*models.RAMCredentials, error) {
+	_, credentials, err := scc.getRAMCredentials(ctx, secretName, scc.stage)
+	return credentials, err
+}
+
+// GetECSCredentials 获取ECS托管凭据的用户名及口令或SSH密钥对
+func (scc *SecretManagerCacheClient) GetECSCredentials(secretName string) (*models.ECSCredentials, error) {
+	return scc.GetECSCredentialsWithContext(context.Background(), secretName)
+}
+
+// GetECSCredentialsWithContext 获取ECS托管凭据的用户名及口令或SSH密钥对，ctx用于控制缓存未命中时请求KMS的超时和取消
+func (scc *SecretManagerCacheClient) GetECSCredentialsWithContext(ctx context.Context, secretName string) (*models.ECSCredentials, error) {
+	credentials := &models.ECSCredentials{}
+	_, err := scc.getManagedCredentials(ctx, secretName, scc.stage, credentials, utils.SecretTypeECS)
+	if err != nil {
+		return nil, err
+	}
+	if credentials.UserName == "" {
+		return nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: "UserName"}
+	}
+	if credentials.Password == "" && credentials.PrivateKey == "" {
+		return nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: "Password"}
+	}
+	return credentials, nil
+}
+
+// getRAMCredentials 获取RAM托管凭据的AccessKey及其所属的凭据信息
+func (scc *SecretManagerCacheClient) getRAMCredentials(ctx context.Context, secretName, stage string) (*models.SecretInfo, *models.RAMCredentials, error) {
+	credentials := &models.RAMCredentials{}
+	secretInfo, err := scc.getManagedCredentials(ctx, secretName, stage, credentials, utils.SecretTypeRAMCredentials)
+	if err != nil {
+		return nil, nil, err
+	}
+	if credentials.AccessKeyId == "" {
+		return nil, nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: "AccessKeyId"}
+	}
+	if credentials.AccessKeySecret == "" {
+		return nil, nil, &SecretFieldNotFoundError{SecretName: secretName, FieldPath: "AccessKeySecret"}
+	}
+	return secretInfo, credentials, nil
+}
+
+// getManagedCredentials 校验凭据类型后将凭据值解析到out
+func (scc *SecretManagerCacheClient) getManagedCredentials(ctx context.Context, secretName, stage string, out interface{}, secretTypes ...string) (*models.SecretInfo, error) {
+	secretInfo, err := scc.getSecretInfo(ctx, secretName, stage)
+	if err != nil {
+		return nil, err
+	}
+	matched := false
+	for _, secretType := range secretTypes {
+		if secretInfo.SecretType == secretType {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil, &SecretTypeMismatchError{SecretName: secretName, ExpectedTypes: secretTypes, ActualType: secretInfo.SecretType}
+	}
+	secretInfo, parsed, err := scc.parseSecretValue(secretName, stage, secretInfo)
+	if err != nil {
+		return nil, err
+	}
+	return secretInfo, decodeJSONValue(secretInfo, parsed, reflect.ValueOf(out))
+}
@@ -0,0 +1,156 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/protected"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+)
+
+// maxProtectedValueRetries 读取期间凭据被刷新替换时的最大重试次数
+const maxProtectedValueRetries = 3
+
+// UseSecretValue 在回调中访问凭据值，二进制凭据为解码后的原始字节
+// 开启受保护凭据值时value直接指向受保护内存，回调返回后不得继续持有或修改value，否则value为临时副本并在回调返回后清零
+func (scc *SecretManagerCacheClient) UseSecretValue(secretName string, fn func(value []byte) error) error {
+	return scc.UseSecretValueWithContext(context.Background(), secretName, fn)
+}
+
+// UseSecretValueWithContext 在回调中访问凭据值，支持通过ctx取消或设置超时
+func (scc *SecretManagerCacheClient) UseSecretValueWithContext(ctx context.Context, secretName string, fn func(value []byte) error) error {
+	if fn == nil {
+		return errors.New(fmt.Sprintf("the argument fn must not be nil"))
+	}
+	var err error
+	for i := 0; i < maxProtectedValueRetries; i++ {
+		var secretInfo *models.SecretInfo
+		secretInfo, err = scc.getCachedSecretInfo(ctx, secretName, scc.stage)
+		if err != nil {
+			return err
+		}
+		if secretInfo.ProtectedValue == nil {
+			value, inErr := scc.getSecretValueBytes(secretInfo)
+			if inErr != nil {
+				return inErr
+			}
+			defer protected.Wipe(value)
+			return fn(value)
+		}
+		err = secretInfo.ProtectedValue.Use(fn)
+		if err != protected.ErrBufferDestroyed {
+			return err
+		}
+	}
+	return err
+}
+
+// getSecretValueBytes 获取未受保护凭据值的字节副本
+func (scc *SecretManagerCacheClient) getSecretValueBytes(secretInfo *models.SecretInfo) ([]byte, error) {
+	if scc.legacyBinaryValue || utils.BinaryDataType != secretInfo.SecretDataType {
+		return []byte(secretInfo.SecretValue), nil
+	}
+	if secretInfo.SecretValueByteBuffer != nil {
+		return append(make([]byte, 0, len(secretInfo.SecretValueByteBuffer)), secretInfo.SecretValueByteBuffer...), nil
+	}
+	byteBuffer, err := utils.DecodeBinarySecretValue(secretInfo.SecretValue)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("the secret named[%s] binary value decode failed, %v", secretInfo.SecretName, err))
+	}
+	return byteBuffer, nil
+}
+
+// protectSecretInfo 开启受保护凭据值时将凭据值移入受保护内存，返回不含明文凭据值的副本
+func (scc *SecretManagerCacheClient) protectSecretInfo(secretInfo *models.SecretInfo) (*models.SecretInfo, error) {
+	if !scc.protectedValues || secretInfo.ProtectedValue != nil {
+		return secretInfo, nil
+	}
+	value, err := scc.getSecretValueBytes(secretInfo)
+	if err != nil {
+		return nil, err
+	}
+	protectedInfo := *secretInfo
+	protectedInfo.SecretValue = ""
+	protectedInfo.SecretValueByteBuffer = nil
+	protectedInfo.ProtectedValue = protected.NewBuffer(value)
+	protected.Wipe(value)
+	return &protectedInfo, nil
+}
+
+// exposeSecretInfo 返回填充了明文凭据值的副本，用于兼容返回SecretInfo的接口
+func (scc *SecretManagerCacheClient) exposeSecretInfo(secretInfo *models.SecretInfo) (*models.SecretInfo, error) {
+	if secretInfo == nil || secretInfo.ProtectedValue == nil {
+		return secretInfo, nil
+	}
+	exposedInfo := *secretInfo
+	exposedInfo.ProtectedValue = nil
+	err := secretInfo.ProtectedValue.Use(func(value []byte) error {
+		if !scc.legacyBinaryValue && utils.BinaryDataType == secretInfo.SecretDataType {
+			exposedInfo.SecretValueByteBuffer = append(make([]byte, 0, len(value)), value...)
+			exposedInfo.SecretValue = base64.StdEncoding.EncodeToString(value)
+		} else {
+			exposedInfo.SecretValue = string(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &exposedInfo, nil
+}
+
+// swapProtectedValue 记录key对应的受保护内存，返回被替换的受保护内存
+func (scc *SecretManagerCacheClient) swapProtectedValue(key string, buffer *protected.Buffer) *protected.Buffer {
+	if buffer == nil || scc.protectedValueMap == nil {
+		return nil
+	}
+	var old *protected.Buffer
+	scc.protectedValueMap.Upsert(key, buffer, func(exists bool, valueInMap interface{}, newValue interface{}) interface{} {
+		if exists {
+			old, _ = valueInMap.(*protected.Buffer)
+		}
+		return newValue
+	})
+	if old == buffer {
+		return nil
+	}
+	return old
+}
+
+// destroyProtectedValue 清零key对应的受保护内存
+func (scc *SecretManagerCacheClient) destroyProtectedValue(key string) {
+	if scc.protectedValueMap == nil {
+		return
+	}
+	if v, ok := scc.protectedValueMap.Pop(key); ok {
+		v.(*protected.Buffer).Destroy()
+	}
+}
+
+// destroyAllProtectedValues 清零所有受保护内存
+func (scc *SecretManagerCacheClient) destroyAllProtectedValues() {
+	if scc.protectedValueMap == nil {
+		return
+	}
+	for _, key := range scc.protectedValueMap.Keys() {
+		scc.destroyProtectedValue(key)
+	}
+}
+
+func isProtectedValueEqual(oldValue, newValue *protected.Buffer) bool {
+	if oldValue == nil || newValue == nil || oldValue == newValue {
+		return oldValue == newValue
+	}
+	equal := false
+	_ = oldValue.Use(func(o []byte) error {
+		return newValue.Use(func(n []byte) error {
+			equal = bytes.Equal(o, n)
+			return nil
+		})
+	})
+	return equal
+}
@@ -0,0 +1,135 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/protected"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretCacheClient_ProtectedValues(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("protected_secret", "v1", `{"password":"value1","ttl":60000}`)
+	client := newMockCacheClient(smc)
+	client.protectedValues = true
+	client.secretTTLMap["protected_secret"] = 60 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	cacheSecretInfo, err := client.cacheSecretStoreStrategy.GetCacheSecretInfo("protected_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "", cacheSecretInfo.SecretInfo.SecretValue)
+	oldBuffer := cacheSecretInfo.SecretInfo.ProtectedValue
+	assert.NotNil(t, oldBuffer)
+
+	value, err := client.GetStringValue("protected_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"password":"value1","ttl":60000}`, value)
+	password, err := client.GetSecretField("protected_secret", "password")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", password)
+	assert.Equal(t, 0, len(client.parsedValueMap.Keys()))
+
+	err = client.UseSecretValue("protected_secret", func(value []byte) error {
+		assert.Equal(t, []byte(`{"password":"value1","ttl":60000}`), value)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	smc.putSecret("protected_secret", "v2", `{"password":"value2","ttl":60000}`)
+	ok, err := client.RefreshNow("protected_secret")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	_, err = oldBuffer.Copy()
+	assert.Equal(t, protected.ErrBufferDestroyed, err)
+	value, err = client.GetStringValue("protected_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"password":"value2","ttl":60000}`, value)
+
+	cacheSecretInfo, err = client.cacheSecretStoreStrategy.GetCacheSecretInfo("protected_secret")
+	assert.Nil(t, err)
+	currentBuffer := cacheSecretInfo.SecretInfo.ProtectedValue
+	assert.Nil(t, client.RemoveSecret("protected_secret"))
+	_, err = currentBuffer.Copy()
+	assert.Equal(t, protected.ErrBufferDestroyed, err)
+}
+
+func TestSecretCacheClient_ProtectedValuesListener(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("protected_listener_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	client.protectedValues = true
+	client.secretTTLMap["protected_listener_secret"] = 60 * 1000
+	changed := make(chan [2][]byte, 1)
+	client.AddSecretChangeListener("protected_listener_secret", func(oldSecretInfo, newSecretInfo *models.SecretInfo) {
+		oldValue, _ := oldSecretInfo.ProtectedValue.Copy()
+		newValue, _ := newSecretInfo.ProtectedValue.Copy()
+		changed <- [2][]byte{oldValue, newValue}
+	})
+	assert.Nil(t, client.Init())
+
+	smc.putSecret("protected_listener_secret", "v2", "value2")
+	_, err := client.RefreshNow("protected_listener_secret")
+	assert.Nil(t, err)
+	select {
+	case values := <-changed:
+		assert.Equal(t, []byte("value1"), values[0])
+		assert.Equal(t, []byte("value2"), values[1])
+	case <-time.After(3 * time.Second):
+		t.Fatal("secret change listener was not called")
+	}
+
+	cacheSecretInfo, err := client.cacheSecretStoreStrategy.GetCacheSecretInfo("protected_listener_secret")
+	assert.Nil(t, err)
+	assert.Nil(t, client.Close())
+	_, err = cacheSecretInfo.SecretInfo.ProtectedValue.Copy()
+	assert.Equal(t, protected.ErrBufferDestroyed, err)
+}
+
+func TestSecretCacheClient_ProtectedBinaryValue(t *testing.T) {
+	secretData := []byte{0x00, 0x01, 0xfe, 0xff}
+	smc := newMockSecretManagerClient()
+	smc.putBinarySecret("protected_binary_secret", "v1", secretData)
+	client := newMockCacheClient(smc)
+	client.protectedValues = true
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	var retained []byte
+	err := client.UseSecretValue("protected_binary_secret", func(value []byte) error {
+		retained = value
+		assert.Equal(t, secretData, value)
+		return nil
+	})
+	assert.Nil(t, err)
+	value, err := client.GetBinaryValue("protected_binary_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, secretData, value)
+
+	// 未开启受保护凭据值时回调中的value为临时副本，回调返回后清零
+	plainClient := newMockCacheClient(smc)
+	assert.Nil(t, plainClient.Init())
+	defer plainClient.Close()
+	err = plainClient.UseSecretValue("protected_binary_secret", func(value []byte) error {
+		retained = value
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, make([]byte, len(secretData)), retained)
+}
+
+func TestSecretCacheClient_ProtectedValuesFileCache(t *testing.T) {
+	cacheSecretPath, err := ioutil.TempDir("", "secrets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(cacheSecretPath)
+
+	client := newMockCacheClient(newMockSecretManagerClient())
+	client.protectedValues = true
+	client.cacheSecretStoreStrategy = cache.NewFileCacheSecretStoreStrategy(cacheSecretPath, true, "1234abcd")
+	assert.NotNil(t, client.Init())
+}
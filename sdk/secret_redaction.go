@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"encoding/json"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+)
+
+// getScrubValues 获取凭据中需要在日志中脱敏的值，JSON凭据额外包含所有字符串字段值
+func getScrubValues(secretInfo *models.SecretInfo) []string {
+	if secretInfo == nil || secretInfo.SecretValue == "" {
+		return nil
+	}
+	values := []string{secretInfo.SecretValue}
+	if utils.TextDataType != secretInfo.SecretDataType {
+		return values
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(secretInfo.SecretValue), &parsed); err != nil {
+		return values
+	}
+	return appendJSONStringValues(values, parsed)
+}
+
+func appendJSONStringValues(values []string, node interface{}) []string {
+	switch node := node.(type) {
+	case string:
+		values = append(values, node)
+	case map[string]interface{}:
+		for _, v := range node {
+			values = appendJSONStringValues(values, v)
+		}
+	case []interface{}:
+		for _, v := range node {
+			values = appendJSONStringValues(values, v)
+		}
+	}
+	return values
+}
+
+// registerScrubValues 开启日志脱敏时登记key对应的凭据值，并移除被替换的旧值
+func (scc *SecretManagerCacheClient) registerScrubValues(key string, secretInfo *models.SecretInfo) {
+	if !scc.logScrubbing || scc.scrubValueMap == nil {
+		return
+	}
+	values := getScrubValues(secretInfo)
+	logger.GetScrubber().Add(values...)
+	var old []string
+	scc.scrubValueMap.Upsert(key, values, func(exists bool, valueInMap interface{}, newValue interface{}) interface{} {
+		if exists {
+			old, _ = valueInMap.([]string)
+		}
+		return newValue
+	})
+	logger.GetScrubber().Remove(old...)
+}
+
+// unregisterScrubValues 移除key对应的已登记凭据值
+func (scc *SecretManagerCacheClient) unregisterScrubValues(key string) {
+	if scc.scrubValueMap == nil {
+		return
+	}
+	if v, ok := scc.scrubValueMap.Pop(key); ok {
+		logger.GetScrubber().Remove(v.([]string)...)
+	}
+}
+
+func (scc *SecretManagerCacheClient) unregisterAllScrubValues() {
+	if scc.scrubValueMap == nil {
+		return
+	}
+	for _, key := range scc.scrubValueMap.Keys() {
+		scc.unregisterScrubValues(key)
+	}
+}
@@ -0,0 +1,29 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretCacheClient_LogScrubbing(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("scrubbed_secret", "v1", `{"username":"scrub-user","password":"scrub-password-1"}`)
+	client := newMockCacheClient(smc)
+	client.logScrubbing = true
+	client.secretTTLMap["scrubbed_secret"] = 60 * 1000
+	assert.Nil(t, client.Init())
+
+	scrubber := logger.GetScrubber()
+	assert.Equal(t, "user:******, password:******", scrubber.Scrub("user:scrub-user, password:scrub-password-1"))
+
+	smc.putSecret("scrubbed_secret", "v2", `{"username":"scrub-user","password":"scrub-password-2"}`)
+	_, err := client.RefreshNow("scrubbed_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "scrub-password-1", scrubber.Scrub("scrub-password-1"))
+	assert.Equal(t, "******", scrubber.Scrub("scrub-password-2"))
+
+	assert.Nil(t, client.Close())
+	assert.Equal(t, "scrub-user scrub-password-2", scrubber.Scrub("scrub-user scrub-password-2"))
+}
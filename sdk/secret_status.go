@@ -0,0 +1,181 @@
+package sdk
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+)
+
+const (
+	// SecretSourceKMS 凭据值来自KMS
+	SecretSourceKMS = "KMS"
+	// SecretSourceRecovery 凭据值来自容灾恢复
+	SecretSourceRecovery = "Recovery"
+)
+
+// SecretStatus 凭据缓存状态，不包含凭据值
+type SecretStatus struct {
+	SecretName string
+	Stage      string
+	VersionId  string
+	// 最近一次成功刷新的时间戳，单位MS
+	LastRefreshTime int64
+	// 下一次计划刷新的时间戳，单位MS，未添加定时刷新时为0
+	NextRefreshTime int64
+	// 连续获取失败次数
+	ConsecutiveFailures int
+	LastError           string
+	// 最近一次失败的时间戳，单位MS
+	LastErrorTime int64
+	// 最近一次获取的凭据值来源，SecretSourceKMS或SecretSourceRecovery
+	Source string
+}
+
+// CacheStats 缓存统计信息
+type CacheStats struct {
+	Hits            int64
+	Misses          int64
+	Refreshes       int64
+	RefreshFailures int64
+	Secrets         []*SecretStatus
+}
+
+// cacheCounters 缓存统计计数，作为SecretManagerCacheClient首个字段以保证原子操作的64位对齐
+type cacheCounters struct {
+	hits            int64
+	misses          int64
+	refreshes       int64
+	refreshFailures int64
+}
+
+type secretStatusRecord struct {
+	mtx    sync.Mutex
+	status SecretStatus
+}
+
+// Stats 获取缓存统计信息及所有凭据的状态
+func (scc *SecretManagerCacheClient) Stats() *CacheStats {
+	stats := &CacheStats{
+		Hits:            atomic.LoadInt64(&scc.counters.hits),
+		Misses:          atomic.LoadInt64(&scc.counters.misses),
+		Refreshes:       atomic.LoadInt64(&scc.counters.refreshes),
+		RefreshFailures: atomic.LoadInt64(&scc.counters.refreshFailures),
+		Secrets:         []*SecretStatus{},
+	}
+	if scc.statusMap == nil {
+		return stats
+	}
+	for _, key := range scc.statusMap.Keys() {
+		secretName, stage := cache.ParseCacheKey(key)
+		if status := scc.getSecretStatus(secretName, stage); status != nil {
+			stats.Secrets = append(stats.Secrets, status)
+		}
+	}
+	sort.Slice(stats.Secrets, func(i, j int) bool {
+		if stats.Secrets[i].SecretName != stats.Secrets[j].SecretName {
+			return stats.Secrets[i].SecretName < stats.Secrets[j].SecretName
+		}
+		return stats.Secrets[i].Stage < stats.Secrets[j].Stage
+	})
+	return stats
+}
+
+// SecretStatus 获取指定凭据默认Version Stage的状态，凭据未缓存且未添加定时刷新时返回false
+func (scc *SecretManagerCacheClient) SecretStatus(secretName string) (*SecretStatus, bool) {
+	status := scc.getSecretStatus(secretName, scc.stage)
+	return status, status != nil
+}
+
+func (scc *SecretManagerCacheClient) getSecretStatus(secretName, stage string) *SecretStatus {
+	if scc.statusMap == nil {
+		return nil
+	}
+	key := cache.CacheKey(secretName, stage)
+	v, ok := scc.statusMap.Get(key)
+	if !ok {
+		return nil
+	}
+	record := v.(*secretStatusRecord)
+	record.mtx.Lock()
+	status := record.status
+	record.mtx.Unlock()
+	if executeTime, ok := scc.scheduledMap.Get(key); ok {
+		status.NextRefreshTime, _ = executeTime.(int64)
+	}
+	return &status
+}
+
+func (scc *SecretManagerCacheClient) updateSecretStatus(secretName, stage string, update func(status *SecretStatus)) {
+	if scc.statusMap == nil {
+		return
+	}
+	key := cache.CacheKey(secretName, stage)
+	scc.statusMap.SetIfAbsent(key, &secretStatusRecord{
+		status: SecretStatus{SecretName: secretName, Stage: stage},
+	})
+	v, ok := scc.statusMap.Get(key)
+	if !ok {
+		return
+	}
+	record := v.(*secretStatusRecord)
+	record.mtx.Lock()
+	update(&record.status)
+	record.mtx.Unlock()
+}
+
+// recordFetchSuccess 记录从KMS或容灾恢复获取到凭据值
+func (scc *SecretManagerCacheClient) recordFetchSuccess(secretName, stage, source string) {
+	scc.updateSecretStatus(secretName, stage, func(status *SecretStatus) {
+		status.Source = source
+		if source == SecretSourceKMS {
+			status.ConsecutiveFailures = 0
+		}
+	})
+}
+
+// recordFetchFailure 记录从KMS获取凭据值失败，仅记录已缓存、已添加定时刷新或需要预加载的凭据，避免状态无限增长
+func (scc *SecretManagerCacheClient) recordFetchFailure(secretName, stage string, err error) {
+	if !scc.isSecretStatusTracked(secretName, stage) {
+		return
+	}
+	scc.updateSecretStatus(secretName, stage, func(status *SecretStatus) {
+		status.ConsecutiveFailures++
+		status.LastError = err.Error()
+		status.LastErrorTime = scc.nowMillis()
+	})
+}
+
+// isSecretStatusTracked 判断是否需要记录凭据状态
+func (scc *SecretManagerCacheClient) isSecretStatusTracked(secretName, stage string) bool {
+	key := cache.CacheKey(secretName, stage)
+	if (scc.statusMap != nil && scc.statusMap.Has(key)) || scc.scheduledMap.Has(key) ||
+		(scc.cachedStageMap != nil && scc.cachedStageMap.Has(key)) {
+		return true
+	}
+	if stage != scc.stage {
+		return false
+	}
+	_, ok := scc.getSecretTTL(secretName)
+	return ok
+}
+
+// recordRefresh 记录凭据刷新结果
+func (scc *SecretManagerCacheClient) recordRefresh(secretName, stage, versionId string, err error) {
+	if err != nil {
+		atomic.AddInt64(&scc.counters.refreshFailures, 1)
+		return
+	}
+	atomic.AddInt64(&scc.counters.refreshes, 1)
+	scc.updateSecretStatus(secretName, stage, func(status *SecretStatus) {
+		status.VersionId = versionId
+		status.LastRefreshTime = scc.nowMillis()
+	})
+}
+
+func (scc *SecretManagerCacheClient) removeSecretStatus(secretName, stage string) {
+	if scc.statusMap != nil {
+		scc.statusMap.Remove(cache.CacheKey(secretName, stage))
+	}
+}
@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretCacheClient_Stats(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("status_secret", "v1", "status_secret_value")
+	client := newMockCacheClient(smc)
+	client.secretTTLMap["status_secret"] = 60 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("status_secret")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfo("status_missing")
+	assert.NotNil(t, err)
+	_, err = client.GetSecretInfo("status_missing")
+	assert.NotNil(t, err)
+
+	status, ok := client.SecretStatus("status_secret")
+	assert.True(t, ok)
+	assert.Equal(t, "v1", status.VersionId)
+	assert.Equal(t, SecretSourceKMS, status.Source)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.True(t, status.LastRefreshTime > 0)
+	assert.True(t, status.NextRefreshTime > status.LastRefreshTime)
+
+	// 未缓存的凭据获取失败不记录状态
+	_, ok = client.SecretStatus("status_missing")
+	assert.False(t, ok)
+
+	_, ok = client.SecretStatus("status_unknown")
+	assert.False(t, ok)
+
+	stats := client.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, int64(1), stats.Refreshes)
+	assert.Equal(t, 1, len(stats.Secrets))
+	assert.Equal(t, "status_secret", stats.Secrets[0].SecretName)
+	assert.False(t, strings.Contains(fmt.Sprintf("%+v", stats.Secrets[0]), "status_secret_value"))
+
+	smc.putError("status_secret", errors.New("kms unreachable"))
+	_, err = client.RefreshNow("status_secret")
+	assert.NotNil(t, err)
+	status, ok = client.SecretStatus("status_secret")
+	assert.True(t, ok)
+	assert.Equal(t, 1, status.ConsecutiveFailures)
+	assert.Equal(t, "kms unreachable", status.LastError)
+	assert.True(t, status.LastRefreshTime > 0)
+
+	assert.Nil(t, client.RemoveSecret("status_secret"))
+	_, ok = client.SecretStatus("status_secret")
+	assert.False(t, ok)
+}
@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"container/list"
+	"strings"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+)
+
+// defaultMaxVersionEntries 默认最多缓存的指定版本凭据数量
+const defaultMaxVersionEntries = 128
+
+// versionCacheKey 生成指定版本凭据的缓存key
+func versionCacheKey(secretName, versionId string) string {
+	return secretName + "#versionId:" + versionId
+}
+
+// getMaxVersionEntries 获取指定版本凭据的最大缓存数量，限制了缓存数量的内存缓存策略使用相同的上限
+func (scc *SecretManagerCacheClient) getMaxVersionEntries() int {
+	if scc.maxVersionEntries > 0 {
+		return scc.maxVersionEntries
+	}
+	if ms, ok := scc.cacheSecretStoreStrategy.(*cache.MemoryCacheSecretStoreStrategy); ok && ms.MaxEntries > 0 {
+		return ms.MaxEntries
+	}
+	return defaultMaxVersionEntries
+}
+
+// getVersionCache 获取已缓存的指定版本凭据，并更新最近访问顺序
+func (scc *SecretManagerCacheClient) getVersionCache(key string) (*models.SecretInfo, bool) {
+	scc.versionCacheMtx.Lock()
+	defer scc.versionCacheMtx.Unlock()
+	v, ok := scc.versionCacheMap.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if element, ok := scc.versionElementMap[key]; ok {
+		scc.versionLRU.MoveToFront(element)
+	}
+	return v.(*models.SecretInfo), true
+}
+
+// putVersionCache 缓存指定版本凭据，超过最大缓存数量时淘汰最近最少访问的版本
+func (scc *SecretManagerCacheClient) putVersionCache(key string, secretInfo *models.SecretInfo) {
+	scc.versionCacheMtx.Lock()
+	if scc.versionLRU == nil {
+		scc.versionLRU = list.New()
+		scc.versionElementMap = make(map[string]*list.Element)
+	}
+	scc.versionCacheMap.Set(key, secretInfo)
+	if element, ok := scc.versionElementMap[key]; ok {
+		scc.versionLRU.MoveToFront(element)
+	} else {
+		scc.versionElementMap[key] = scc.versionLRU.PushFront(key)
+	}
+	var evicted []string
+	for scc.versionLRU.Len() > scc.getMaxVersionEntries() {
+		evictedKey := scc.versionLRU.Remove(scc.versionLRU.Back()).(string)
+		delete(scc.versionElementMap, evictedKey)
+		scc.versionCacheMap.Remove(evictedKey)
+		evicted = append(evicted, evictedKey)
+	}
+	scc.versionCacheMtx.Unlock()
+	for _, evictedKey := range evicted {
+		scc.destroyProtectedValue(evictedKey)
+		scc.unregisterScrubValues(evictedKey)
+	}
+}
+
+// removeVersionCache 删除凭据所有已缓存的指定版本
+func (scc *SecretManagerCacheClient) removeVersionCache(secretName string) {
+	prefix := versionCacheKey(secretName, "")
+	var removed []string
+	scc.versionCacheMtx.Lock()
+	for _, key := range scc.versionCacheMap.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			scc.versionCacheMap.Remove(key)
+			if element, ok := scc.versionElementMap[key]; ok {
+				scc.versionLRU.Remove(element)
+				delete(scc.versionElementMap, key)
+			}
+			removed = append(removed, key)
+		}
+	}
+	scc.versionCacheMtx.Unlock()
+	for _, key := range removed {
+		scc.destroyProtectedValue(key)
+		scc.unregisterScrubValues(key)
+	}
+}
+
+// clearVersionCache 清空所有已缓存的指定版本
+func (scc *SecretManagerCacheClient) clearVersionCache() {
+	scc.versionCacheMtx.Lock()
+	defer scc.versionCacheMtx.Unlock()
+	if scc.versionCacheMap != nil {
+		scc.versionCacheMap.Clear()
+	}
+	scc.versionLRU = nil
+	scc.versionElementMap = nil
+}
@@ -1,17 +1,24 @@
 package sdk
 
 import (
+	"container/list"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/alibabacloud-go/tea/tea"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	kms "github.com/alibabacloud-go/kms-20160120/v3/client"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/metrics"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/protected"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/service"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/tracing"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
 	cmap "github.com/orcaman/concurrent-map"
 )
@@ -20,9 +27,16 @@ const (
 	// defaultTtl 默认TTL时间
 	defaultTtl                 int64 = 60 * 60 * 1000
 	defaultJsonTtlPropertyName       = "ttl"
+	// defaultPreloadConcurrency 默认批量加载凭据的并发数
+	defaultPreloadConcurrency = 8
+	// defaultRefreshWorkers 默认执行定时刷新的工作协程数
+	defaultRefreshWorkers = 4
+	// defaultMaxNegativeCacheEntries 默认最多缓存的不可重试异常数量
+	defaultMaxNegativeCacheEntries = 10000
 )
 
 type SecretManagerCacheClient struct {
+	counters                 cacheCounters
 	jsonTTLPropertyName      string
 	stage                    string
 	secretManagerClient      service.SecretManagerClient
@@ -30,34 +44,137 @@ type SecretManagerCacheClient struct {
 	refreshSecretStrategy    service.RefreshSecretStrategy
 	cacheHook                cache.SecretCacheHook
 	secretTTLMap             map[string]int64
+	secretTTLMtx             sync.RWMutex
+	// 获取当前时间，为nil时使用time.Now，用于判断缓存过期
+	clock func() time.Time
+	// 凭据过期后允许继续返回旧值的最大时长，单位MS，小于等于0表示不开启
+	maxStaleness int64
+	// 凭据不存在或无权限等异常的缓存时长，单位MS，小于等于0表示不缓存
+	negativeCacheTTL int64
+	// 二进制凭据兼容旧行为，不解码并由GetBinaryValue返回base64文本的字节
+	legacyBinaryValue bool
+	// 凭据值存放于受保护内存，替换及淘汰时清零
+	protectedValues bool
+	// 在CommonLogger输出的日志中脱敏已缓存的凭据值
+	logScrubbing bool
+	// 初始化时预加载凭据失败的处理策略
+	initFailurePolicy InitFailurePolicy
+	// InitRequireListed策略下必须加载成功的凭据
+	requiredSecrets []string
+	initReportMtx   sync.Mutex
+	initLoaded      map[string]struct{}
+	initFailed      map[string]error
+	// 批量加载凭据的最大并发数，小于等于0时使用默认值
+	preloadConcurrency int
 
+	// 定时刷新工作协程数，小于等于0时使用默认值
+	refreshWorkers int
+	scheduler      *refreshScheduler
+	// 已添加定时刷新的凭据，value为计划执行时间戳
 	scheduledMap     cmap.ConcurrentMap
 	secretNameMtx    sync.Mutex
 	secretNameMtxMap map[string]*sync.Mutex
+	// 凭据锁的持有状态，value为*secretLockState，由secretNameMtx保护
+	secretLockStateMap map[string]*secretLockState
+
+	listenerMtx       sync.RWMutex
+	secretListenerMap map[string][]SecretChangeListener
+	globalListeners   []SecretChangeListener
+	notifyMtx         sync.Mutex
+	notifyQueueMap    map[string]*secretChangeQueue
+
+	revalidatingMap cmap.ConcurrentMap
+	// 已缓存的凭据Version Stage，key为缓存key
+	cachedStageMap  cmap.ConcurrentMap
+	parsedValueMap  cmap.ConcurrentMap
+	versionCacheMap cmap.ConcurrentMap
+	// 指定版本凭据的最大缓存数量，小于等于0时使用默认值
+	maxVersionEntries int
+	versionCacheMtx   sync.Mutex
+	versionLRU        *list.List
+	versionElementMap map[string]*list.Element
+	statusMap         cmap.ConcurrentMap
+	// 缓存的不可重试异常，value为*negativeCacheEntry
+	negativeCacheMap cmap.ConcurrentMap
+	// 最多缓存的不可重试异常数量，小于等于0时使用默认值
+	maxNegativeCacheEntries int
+	negativeCacheMtx        sync.Mutex
+	// 最早过期的已缓存异常的过期时间戳，达到数量上限且未到该时间时无需清理
+	negativeCacheNextSweep int64
+	// 开启受保护凭据值时缓存中的受保护内存，value为*protected.Buffer
+	protectedValueMap cmap.ConcurrentMap
+	// 开启日志脱敏时已登记的凭据值，value为[]string
+	scrubValueMap cmap.ConcurrentMap
+	metrics       metrics.Metrics
+	tracer        tracing.Tracer
+
+	closeMtx sync.RWMutex
+	closed   bool
+	// 正在执行的刷新任务
+	refreshWg sync.WaitGroup
+	// 后台刷新使用的context，关闭超时后取消
+	backgroundCtx    context.Context
+	backgroundCancel context.CancelFunc
+}
+
+// SecretInfoResult 批量获取凭据时单个凭据的结果
+type SecretInfoResult struct {
+	SecretInfo *models.SecretInfo
+	Err        error
+}
+
+type negativeCacheEntry struct {
+	err error
+	// 过期时间戳，单位MS
+	expireTimestamp int64
 }
 
 type runnable interface {
 	getRunnable() func()
 }
 
+// secretLockState 凭据锁的引用计数及持有期间凭据被淘汰的次数
+type secretLockState struct {
+	refs      int
+	evictions int
+}
+
 type refreshSecretTask struct {
 	client     *SecretManagerCacheClient
 	secretName string
+	stage      string
 }
 
 func NewSecretCacheClient() *SecretManagerCacheClient {
+	backgroundCtx, backgroundCancel := context.WithCancel(context.Background())
 	return &SecretManagerCacheClient{
 		jsonTTLPropertyName: defaultJsonTtlPropertyName,
 		stage:               utils.StageAcsCurrent,
 		secretTTLMap:        make(map[string]int64),
 		scheduledMap:        cmap.New(),
 		secretNameMtxMap:    make(map[string]*sync.Mutex),
+		revalidatingMap:     cmap.New(),
+		cachedStageMap:      cmap.New(),
+		parsedValueMap:      cmap.New(),
+		versionCacheMap:     cmap.New(),
+		statusMap:           cmap.New(),
+		negativeCacheMap:    cmap.New(),
+		protectedValueMap:   cmap.New(),
+		scrubValueMap:       cmap.New(),
+		backgroundCtx:       backgroundCtx,
+		backgroundCancel:    backgroundCancel,
 	}
 }
 
 func (scc *SecretManagerCacheClient) Init() error {
+	if scc.metrics == nil {
+		scc.metrics = metrics.NewNoopMetrics()
+	}
+	if scc.tracer == nil {
+		scc.tracer = tracing.NewNoopTracer()
+	}
 	if scc.secretManagerClient == nil {
-		scc.secretManagerClient = service.NewDefaultSecretManagerClientBuilder().Build()
+		scc.secretManagerClient = service.NewDefaultSecretManagerClientBuilder().WithMetrics(scc.metrics).WithTracer(scc.tracer).Build()
 	}
 	err := scc.secretManagerClient.Init()
 	if err != nil {
@@ -66,10 +183,24 @@ func (scc *SecretManagerCacheClient) Init() error {
 	if scc.cacheSecretStoreStrategy == nil {
 		scc.cacheSecretStoreStrategy = cache.NewMemoryCacheSecretStoreStrategy()
 	}
+	if scc.protectedValues {
+		if _, ok := scc.cacheSecretStoreStrategy.(*cache.FileCacheSecretStoreStrategy); ok {
+			return errors.New(fmt.Sprintf("protected values do not support FileCacheSecretStoreStrategy"))
+		}
+		if err = protected.DisableCoreDump(); err != nil {
+			return err
+		}
+	}
 	err = scc.cacheSecretStoreStrategy.Init()
 	if err != nil {
 		return err
 	}
+	if memory, ok := scc.cacheSecretStoreStrategy.(*cache.MemoryCacheSecretStoreStrategy); ok && memory.Clock == nil {
+		memory.Clock = scc.clock
+	}
+	if evictable, ok := scc.cacheSecretStoreStrategy.(cache.EvictableSecretCacheStoreStrategy); ok {
+		evictable.SetEvictionListener(scc.onSecretEvicted)
+	}
 	if scc.refreshSecretStrategy == nil {
 		scc.refreshSecretStrategy = service.NewDefaultRefreshSecretStrategy(scc.jsonTTLPropertyName)
 	}
@@ -84,45 +215,171 @@ func (scc *SecretManagerCacheClient) Init() error {
 	if err != nil {
 		return err
 	}
-	for secretName := range scc.secretTTLMap {
-		secretInfo, err := scc.getSecretValue(secretName)
-		if err != nil {
-			logger.GetCommonLogger(utils.ModeName).Errorf("action:initSecretCacheClient", err)
-			if scc.judgeSkipRefreshException(err) {
-				return err
-			}
-		}
-		err = scc.storeAndRefresh(secretName, secretInfo)
-		if err != nil {
-			return err
-		}
+	if scc.revalidatingMap == nil {
+		scc.revalidatingMap = cmap.New()
+	}
+	if scc.cachedStageMap == nil {
+		scc.cachedStageMap = cmap.New()
+	}
+	if scc.parsedValueMap == nil {
+		scc.parsedValueMap = cmap.New()
+	}
+	if scc.versionCacheMap == nil {
+		scc.versionCacheMap = cmap.New()
+	}
+	if scc.statusMap == nil {
+		scc.statusMap = cmap.New()
+	}
+	if scc.negativeCacheMap == nil {
+		scc.negativeCacheMap = cmap.New()
+	}
+	if scc.protectedValueMap == nil {
+		scc.protectedValueMap = cmap.New()
 	}
+	if scc.scrubValueMap == nil {
+		scc.scrubValueMap = cmap.New()
+	}
+	if scc.backgroundCtx == nil {
+		scc.backgroundCtx, scc.backgroundCancel = context.WithCancel(context.Background())
+	}
+	if scc.scheduler == nil {
+		scc.scheduler = newRefreshScheduler(scc.refreshWorkers, scc.clock)
+	}
+	if err = scc.preloadSecrets(); err != nil {
+		// 释放已加载的凭据、定时刷新任务及KMS客户端等资源
+		_ = scc.Close()
+		return err
+	}
+	// 预加载通过初始化失败策略校验后再开始执行定时刷新
+	scc.scheduler.start()
 	logger.GetCommonLogger(utils.ModeName).Infof("secretCacheClient init success")
 	return nil
 }
 
+// 批量获取多个凭据的secretInfo信息，按preloadConcurrency并发获取，返回以凭据名称为key的结果
+func (scc *SecretManagerCacheClient) GetSecretInfos(secretNames ...string) map[string]*SecretInfoResult {
+	return scc.GetSecretInfosWithContext(context.Background(), secretNames...)
+}
+
+// 批量获取多个凭据的secretInfo信息，支持通过ctx取消或设置超时
+func (scc *SecretManagerCacheClient) GetSecretInfosWithContext(ctx context.Context, secretNames ...string) map[string]*SecretInfoResult {
+	names := make([]string, 0, len(secretNames))
+	seen := make(map[string]bool, len(secretNames))
+	for _, secretName := range secretNames {
+		if !seen[secretName] {
+			seen[secretName] = true
+			names = append(names, secretName)
+		}
+	}
+	secretInfos := make([]*models.SecretInfo, len(names))
+	errs := scc.runConcurrently(names, func(index int, secretName string) error {
+		secretInfo, err := scc.GetSecretInfoWithContext(ctx, secretName)
+		secretInfos[index] = secretInfo
+		return err
+	})
+	results := make(map[string]*SecretInfoResult, len(names))
+	for i, secretName := range names {
+		results[secretName] = &SecretInfoResult{SecretInfo: secretInfos[i], Err: errs[i]}
+	}
+	return results
+}
+
 // 根据凭据名称获取secretInfo信息
 func (scc *SecretManagerCacheClient) GetSecretInfo(secretName string) (*models.SecretInfo, error) {
+	return scc.GetSecretInfoWithContext(context.Background(), secretName)
+}
+
+// 根据凭据名称获取secretInfo信息，缓存未命中时的远程调用可通过ctx取消或设置超时
+func (scc *SecretManagerCacheClient) GetSecretInfoWithContext(ctx context.Context, secretName string) (*models.SecretInfo, error) {
+	return scc.getSecretInfo(ctx, secretName, scc.stage)
+}
+
+// 根据凭据名称和Version Stage获取secretInfo信息，不同Version Stage分别缓存和刷新
+func (scc *SecretManagerCacheClient) GetSecretInfoByStage(secretName, stage string) (*models.SecretInfo, error) {
+	return scc.GetSecretInfoByStageWithContext(context.Background(), secretName, stage)
+}
+
+// 根据凭据名称和Version Stage获取secretInfo信息，支持通过ctx取消或设置超时
+func (scc *SecretManagerCacheClient) GetSecretInfoByStageWithContext(ctx context.Context, secretName, stage string) (*models.SecretInfo, error) {
+	if stage == "" {
+		return nil, errors.New(fmt.Sprintf("the argument stage must not be empty"))
+	}
+	return scc.getSecretInfo(ctx, secretName, stage)
+}
+
+// getSecretInfo 获取凭据信息，开启受保护凭据值时返回填充了明文凭据值的副本
+func (scc *SecretManagerCacheClient) getSecretInfo(ctx context.Context, secretName, stage string) (*models.SecretInfo, error) {
+	var err error
+	for i := 0; i < maxProtectedValueRetries; i++ {
+		var secretInfo *models.SecretInfo
+		secretInfo, err = scc.getCachedSecretInfo(ctx, secretName, stage)
+		if err != nil {
+			return nil, err
+		}
+		secretInfo, err = scc.exposeSecretInfo(secretInfo)
+		if err != protected.ErrBufferDestroyed {
+			return secretInfo, err
+		}
+	}
+	return nil, err
+}
+
+func (scc *SecretManagerCacheClient) getCachedSecretInfo(ctx context.Context, secretName, stage string) (*models.SecretInfo, error) {
+	ctx, span := scc.getTracer().Start(ctx, tracing.SpanGetSecretInfo)
+	defer span.End()
+	span.SetAttribute(tracing.AttributeSecretName, secretName)
+	span.SetAttribute(tracing.AttributeStage, stage)
+	secretInfo, err := scc.loadSecretInfo(ctx, span, secretName, stage)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return secretInfo, err
+}
+
+func (scc *SecretManagerCacheClient) loadSecretInfo(ctx context.Context, span tracing.Span, secretName, stage string) (*models.SecretInfo, error) {
+	if scc.isClosed() {
+		return nil, ErrClientClosed
+	}
 	if secretName == "" {
 		return nil, errors.New(fmt.Sprintf("the argument secretName must not be empty"))
 	}
-	cacheSecretInfo, err := scc.cacheSecretStoreStrategy.GetCacheSecretInfo(secretName)
+	cacheSecretInfo, err := scc.getCacheSecretInfo(secretName, stage)
 	if err == nil && !scc.judgeCacheExpire(cacheSecretInfo) {
+		atomic.AddInt64(&scc.counters.hits, 1)
+		scc.metrics.IncCacheHit()
+		span.SetAttribute(tracing.AttributeCacheHit, true)
+		return scc.cacheHook.Get(cacheSecretInfo)
+	} else if err == nil && scc.judgeServeStale(cacheSecretInfo) {
+		atomic.AddInt64(&scc.counters.hits, 1)
+		scc.metrics.IncCacheHit()
+		span.SetAttribute(tracing.AttributeCacheHit, true)
+		scc.revalidate(secretName, stage)
 		return scc.cacheHook.Get(cacheSecretInfo)
 	} else {
-		lck := scc.getLock(secretName)
+		atomic.AddInt64(&scc.counters.misses, 1)
+		scc.metrics.IncCacheMiss()
+		span.SetAttribute(tracing.AttributeCacheHit, false)
+		if err := scc.getNegativeCache(secretName, stage); err != nil {
+			return nil, err
+		}
+		key := cache.CacheKey(secretName, stage)
+		lck := scc.getLock(key)
 		lck.Lock()
-		defer lck.Unlock()
-		cacheSecretInfo, err = scc.cacheSecretStoreStrategy.GetCacheSecretInfo(secretName)
+		defer scc.releaseLock(key, lck)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		cacheSecretInfo, err = scc.getCacheSecretInfo(secretName, stage)
 		if err == nil && !scc.judgeCacheExpire(cacheSecretInfo) {
 			return scc.cacheHook.Get(cacheSecretInfo)
 		} else {
-			secretInfo, err := scc.getSecretValue(secretName)
+			secretInfo, err := scc.getSecretValue(ctx, secretName, stage)
 			if err != nil {
+				scc.putNegativeCache(secretName, stage, err)
 				return nil, err
 			}
-			err = scc.storeAndRefreshLocked(secretName, secretInfo)
-			if err != nil {
+			err = scc.storeAndRefreshLocked(ctx, secretName, stage, secretInfo)
+			if err != nil && err != errSecretEvicted {
 				return nil, err
 			}
 			cacheSecretInfo, err = scc.cacheHook.Put(secretInfo)
@@ -137,9 +394,73 @@ func (scc *SecretManagerCacheClient) GetSecretInfo(secretName string) (*models.S
 	}
 }
 
+// 根据凭据名称和VersionId获取指定版本的secretInfo信息，凭据版本不可变，缓存后不会定时刷新
+func (scc *SecretManagerCacheClient) GetSecretInfoByVersion(secretName, versionId string) (*models.SecretInfo, error) {
+	return scc.GetSecretInfoByVersionWithContext(context.Background(), secretName, versionId)
+}
+
+// 根据凭据名称和VersionId获取指定版本的secretInfo信息，支持通过ctx取消或设置超时
+func (scc *SecretManagerCacheClient) GetSecretInfoByVersionWithContext(ctx context.Context, secretName, versionId string) (*models.SecretInfo, error) {
+	if scc.isClosed() {
+		return nil, ErrClientClosed
+	}
+	if secretName == "" {
+		return nil, errors.New(fmt.Sprintf("the argument secretName must not be empty"))
+	}
+	if versionId == "" {
+		return nil, errors.New(fmt.Sprintf("the argument versionId must not be empty"))
+	}
+	key := versionCacheKey(secretName, versionId)
+	// 读取期间指定版本可能被淘汰，受保护内存已清零时重新获取
+	if cached, ok := scc.getVersionCache(key); ok {
+		if secretInfo, err := scc.exposeSecretInfo(cached); err != protected.ErrBufferDestroyed {
+			return secretInfo, err
+		}
+	}
+	lck := scc.getLock(key)
+	lck.Lock()
+	defer scc.releaseLock(key, lck)
+	if cached, ok := scc.getVersionCache(key); ok {
+		if secretInfo, err := scc.exposeSecretInfo(cached); err != protected.ErrBufferDestroyed {
+			return secretInfo, err
+		}
+	}
+	request := &kms.GetSecretValueRequest{}
+	request.SetSecretName(secretName)
+	request.SetVersionId(versionId)
+	request.SetFetchExtendedConfig(true)
+	resp, err := scc.getSecretValueResponse(ctx, request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger.GetCommonLogger(utils.ModeName).Errorf("action:getSecretValueByVersion", err)
+		return nil, err
+	}
+	secretInfo, err := scc.parseSecretInfo(resp)
+	if err != nil {
+		return nil, err
+	}
+	protectedInfo, err := scc.protectSecretInfo(secretInfo)
+	if err != nil {
+		return nil, err
+	}
+	scc.registerScrubValues(key, secretInfo)
+	if old := scc.swapProtectedValue(key, protectedInfo.ProtectedValue); old != nil {
+		old.Destroy()
+	}
+	scc.putVersionCache(key, protectedInfo)
+	return secretInfo, nil
+}
+
 // 根据凭据名称获取凭据存储值文本信息
 func (scc *SecretManagerCacheClient) GetStringValue(secretName string) (string, error) {
-	secretInfo, err := scc.GetSecretInfo(secretName)
+	return scc.GetStringValueWithContext(context.Background(), secretName)
+}
+
+// 根据凭据名称获取凭据存储值文本信息，支持通过ctx取消或设置超时
+func (scc *SecretManagerCacheClient) GetStringValueWithContext(ctx context.Context, secretName string) (string, error) {
+	secretInfo, err := scc.GetSecretInfoWithContext(ctx, secretName)
 	if err != nil {
 		return "", err
 	}
@@ -151,25 +472,197 @@ func (scc *SecretManagerCacheClient) GetStringValue(secretName string) (string,
 
 // 根据凭据名称获取凭据存储的二进制信息
 func (scc *SecretManagerCacheClient) GetBinaryValue(secretName string) ([]byte, error) {
-	secretInfo, err := scc.GetSecretInfo(secretName)
+	return scc.GetBinaryValueWithContext(context.Background(), secretName)
+}
+
+// 根据凭据名称获取凭据存储的二进制信息，支持通过ctx取消或设置超时
+func (scc *SecretManagerCacheClient) GetBinaryValueWithContext(ctx context.Context, secretName string) ([]byte, error) {
+	secretInfo, err := scc.GetSecretInfoWithContext(ctx, secretName)
 	if err != nil {
 		return nil, err
 	}
 	if utils.BinaryDataType != secretInfo.SecretDataType {
 		return nil, errors.New(fmt.Sprintf("the secret named[%s] do not support binary value", secretName))
 	}
-	return []byte(secretInfo.SecretValue), nil
+	if scc.legacyBinaryValue {
+		return []byte(secretInfo.SecretValue), nil
+	}
+	if secretInfo.SecretValueByteBuffer != nil {
+		return append(make([]byte, 0, len(secretInfo.SecretValueByteBuffer)), secretInfo.SecretValueByteBuffer...), nil
+	}
+	// 容灾恢复或自定义缓存策略返回的凭据可能未解码
+	byteBuffer, err := utils.DecodeBinarySecretValue(secretInfo.SecretValue)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("the secret named[%s] binary value decode failed, %v", secretName, err))
+	}
+	return byteBuffer, nil
 }
 
 // 强制刷新指定的凭据名称
 func (scc *SecretManagerCacheClient) RefreshNow(secretName string) (bool, error) {
+	return scc.RefreshNowWithContext(context.Background(), secretName)
+}
+
+// 强制刷新指定的凭据名称，支持通过ctx取消或设置超时
+func (scc *SecretManagerCacheClient) RefreshNowWithContext(ctx context.Context, secretName string) (bool, error) {
 	if secretName == "" {
 		return false, errors.New(fmt.Sprintf("the argument[%s] must not be null", secretName))
 	}
-	return scc.refreshNow(secretName, nil)
+	scc.removeNegativeCache(secretName, scc.stage)
+	return scc.refreshNow(ctx, secretName, scc.stage, nil)
+}
+
+// PauseRefresh 暂停定时刷新，暂停期间到期的刷新任务在恢复后按执行时间依次执行
+func (scc *SecretManagerCacheClient) PauseRefresh() {
+	if scc.scheduler != nil {
+		scc.scheduler.pause()
+	}
+}
+
+// ResumeRefresh 恢复定时刷新
+func (scc *SecretManagerCacheClient) ResumeRefresh() {
+	if scc.scheduler != nil {
+		scc.scheduler.resume()
+	}
+}
+
+// UpcomingRefreshes 按执行时间升序获取最近的limit个待执行刷新任务，limit小于等于0时返回全部
+func (scc *SecretManagerCacheClient) UpcomingRefreshes(limit int) []ScheduledRefresh {
+	if scc.scheduler == nil {
+		return nil
+	}
+	return scc.scheduler.upcoming(limit)
 }
 
+// AddSecret 添加需要预加载并定时刷新的凭据，ttl为凭据刷新间隔，单位MS
+func (scc *SecretManagerCacheClient) AddSecret(secretName string, ttl int64) error {
+	if scc.isClosed() {
+		return ErrClientClosed
+	}
+	if secretName == "" {
+		return errors.New(fmt.Sprintf("the argument secretName must not be empty"))
+	}
+	if ttl <= 0 {
+		return errors.New(fmt.Sprintf("the argument ttl must be greater than 0"))
+	}
+	scc.secretTTLMtx.Lock()
+	oldTTL, existed := scc.secretTTLMap[secretName]
+	scc.secretTTLMap[secretName] = ttl
+	scc.secretTTLMtx.Unlock()
+	scc.removeNegativeCache(secretName, scc.stage)
+	if err := scc.storeAndRefresh(context.Background(), secretName, scc.stage, nil); err != nil {
+		scc.secretTTLMtx.Lock()
+		if existed {
+			scc.secretTTLMap[secretName] = oldTTL
+		} else {
+			delete(scc.secretTTLMap, secretName)
+		}
+		scc.secretTTLMtx.Unlock()
+		return err
+	}
+	return nil
+}
+
+// RemoveSecret 移除凭据，停止所有Version Stage的定时刷新并删除缓存
+func (scc *SecretManagerCacheClient) RemoveSecret(secretName string) error {
+	if scc.isClosed() {
+		return ErrClientClosed
+	}
+	if secretName == "" {
+		return errors.New(fmt.Sprintf("the argument secretName must not be empty"))
+	}
+	scc.secretTTLMtx.Lock()
+	delete(scc.secretTTLMap, secretName)
+	scc.secretTTLMtx.Unlock()
+	for _, stage := range scc.getCachedStages(secretName) {
+		if err := scc.removeSecret(secretName, stage); err != nil {
+			return err
+		}
+	}
+	scc.removeVersionCache(secretName)
+	scc.removeAllNegativeCache(secretName)
+	logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s remove success", secretName)
+	return nil
+}
+
+// ListSecrets 获取需要预加载并定时刷新的凭据名称列表
+func (scc *SecretManagerCacheClient) ListSecrets() []string {
+	scc.secretTTLMtx.RLock()
+	defer scc.secretTTLMtx.RUnlock()
+	secretNames := make([]string, 0, len(scc.secretTTLMap))
+	for secretName := range scc.secretTTLMap {
+		secretNames = append(secretNames, secretName)
+	}
+	sort.Strings(secretNames)
+	return secretNames
+}
+
+// Close 关闭客户端，停止所有定时刷新并等待正在执行的刷新完成
 func (scc *SecretManagerCacheClient) Close() error {
+	if err := scc.Shutdown(context.Background()); err != nil && err != ErrClientClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 关闭客户端，停止所有定时刷新并等待正在执行的刷新完成或ctx结束，关闭后所有接口返回ErrClientClosed
+// ctx结束时不再等待并返回ctx.Err()，缓存及KMS客户端等资源在正在执行的刷新完成后再关闭
+func (scc *SecretManagerCacheClient) Shutdown(ctx context.Context) error {
+	scc.closeMtx.Lock()
+	if scc.closed {
+		scc.closeMtx.Unlock()
+		return ErrClientClosed
+	}
+	scc.closed = true
+	scc.closeMtx.Unlock()
+
+	scc.removeAllRefreshTasks()
+	if scc.scheduler != nil {
+		scc.scheduler.close()
+	}
+	done := make(chan struct{})
+	go func() {
+		scc.refreshWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err := ctx.Err()
+		logger.GetCommonLogger(utils.ModeName).Errorf("action:shutdownSecretCacheClient, waiting for running refresh tasks, %+v", err)
+		if scc.backgroundCancel != nil {
+			scc.backgroundCancel()
+		}
+		go func() {
+			<-done
+			scc.releaseResources()
+		}()
+		return err
+	}
+	if scc.backgroundCancel != nil {
+		scc.backgroundCancel()
+	}
+	scc.releaseResources()
+	return nil
+}
+
+// releaseResources 正在执行的刷新完成后清理缓存并关闭资源
+func (scc *SecretManagerCacheClient) releaseResources() {
+	// 等待期间刷新任务可能重新添加了定时器
+	scc.removeAllRefreshTasks()
+	scc.clearVersionCache()
+	if scc.parsedValueMap != nil {
+		scc.parsedValueMap.Clear()
+	}
+	if scc.cachedStageMap != nil {
+		scc.cachedStageMap.Clear()
+	}
+	scc.destroyAllProtectedValues()
+	scc.unregisterAllScrubValues()
+	scc.closeResources()
+}
+
+func (scc *SecretManagerCacheClient) closeResources() {
 	if scc.cacheSecretStoreStrategy != nil {
 		if err := scc.cacheSecretStoreStrategy.Close(); err != nil {
 			logger.GetCommonLogger(utils.ModeName).Errorf("action:closeCacheSecretStoreStrategy", err)
@@ -190,43 +683,142 @@ func (scc *SecretManagerCacheClient) Close() error {
 			logger.GetCommonLogger(utils.ModeName).Errorf("action:closeCacheHook", err)
 		}
 	}
-	return nil
+}
+
+func (scc *SecretManagerCacheClient) isClosed() bool {
+	scc.closeMtx.RLock()
+	defer scc.closeMtx.RUnlock()
+	return scc.closed
+}
+
+// beginRefresh 登记一个正在执行的刷新任务，客户端已关闭时返回false
+func (scc *SecretManagerCacheClient) beginRefresh() bool {
+	scc.closeMtx.RLock()
+	defer scc.closeMtx.RUnlock()
+	if scc.closed {
+		return false
+	}
+	scc.refreshWg.Add(1)
+	return true
+}
+
+func (scc *SecretManagerCacheClient) getTracer() tracing.Tracer {
+	if scc.tracer == nil {
+		return tracing.NewNoopTracer()
+	}
+	return scc.tracer
+}
+
+func (scc *SecretManagerCacheClient) getBackgroundContext() context.Context {
+	if scc.backgroundCtx == nil {
+		return context.Background()
+	}
+	return scc.backgroundCtx
+}
+
+// runConcurrently 以preloadConcurrency为并发上限对每个凭据名称执行fn，返回与secretNames一一对应的错误
+func (scc *SecretManagerCacheClient) runConcurrently(secretNames []string, fn func(index int, secretName string) error) []error {
+	errs := make([]error, len(secretNames))
+	concurrency := scc.preloadConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultPreloadConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, secretName := range secretNames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(index int, secretName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[index] = fn(index, secretName)
+		}(i, secretName)
+	}
+	wg.Wait()
+	return errs
 }
 
 func (scc *SecretManagerCacheClient) judgeCacheExpire(cacheSecretInfo *models.CacheSecretInfo) bool {
-	ttl := scc.refreshSecretStrategy.ParseTTL(cacheSecretInfo.SecretInfo)
+	return scc.nowMillis() > scc.getCacheExpireTime(cacheSecretInfo)
+}
+
+// getCacheExpireTime 获取缓存过期时间戳，缓存在TTL及刷新策略计划的下一次刷新之前均不会过期
+func (scc *SecretManagerCacheClient) getCacheExpireTime(cacheSecretInfo *models.CacheSecretInfo) int64 {
+	expireTime := cacheSecretInfo.RefreshTimestamp + scc.getCacheTTL(cacheSecretInfo)
+	if cacheSecretInfo.NextRefreshTimestamp > expireTime {
+		expireTime = cacheSecretInfo.NextRefreshTimestamp
+	}
+	return expireTime
+}
+
+// nowMillis 获取当前时间戳，单位MS
+func (scc *SecretManagerCacheClient) nowMillis() int64 {
+	if scc.clock != nil {
+		return scc.clock().UnixNano() / 1e6
+	}
+	return time.Now().UnixNano() / 1e6
+}
+
+// judgeServeStale 判断已过期的缓存是否仍在允许返回旧值的时长内
+func (scc *SecretManagerCacheClient) judgeServeStale(cacheSecretInfo *models.CacheSecretInfo) bool {
+	if scc.maxStaleness <= 0 {
+		return false
+	}
+	return scc.nowMillis() <= scc.getCacheExpireTime(cacheSecretInfo)+scc.maxStaleness
+}
+
+func (scc *SecretManagerCacheClient) getSecretTTL(secretName string) (int64, bool) {
+	scc.secretTTLMtx.RLock()
+	defer scc.secretTTLMtx.RUnlock()
+	ttl, ok := scc.secretTTLMap[secretName]
+	return ttl, ok
+}
+
+// getCacheTTL 获取缓存TTL，优先使用获取凭据时从凭据值中解析的TTL
+func (scc *SecretManagerCacheClient) getCacheTTL(cacheSecretInfo *models.CacheSecretInfo) int64 {
+	ttl := cacheSecretInfo.TTL
 	if ttl <= 0 {
-		if ttl0, ok := scc.secretTTLMap[cacheSecretInfo.SecretInfo.SecretName]; !ok {
+		if ttl0, ok := scc.getSecretTTL(cacheSecretInfo.SecretInfo.SecretName); !ok {
 			ttl = defaultTtl
 		} else {
 			ttl = ttl0
 		}
 	}
-	return (time.Now().UnixNano()/1e6)-cacheSecretInfo.RefreshTimestamp > ttl
+	return ttl
 }
 
-func (scc *SecretManagerCacheClient) getSecretValue(secretName string) (*models.SecretInfo, error) {
+// revalidate 后台刷新已过期的凭据，同一凭据同时只有一个刷新任务
+func (scc *SecretManagerCacheClient) revalidate(secretName, stage string) {
+	key := cache.CacheKey(secretName, stage)
+	if !scc.revalidatingMap.SetIfAbsent(key, struct{}{}) {
+		return
+	}
+	go func() {
+		defer scc.revalidatingMap.Remove(key)
+		if _, err := scc.refreshNow(scc.getBackgroundContext(), secretName, stage, nil); err != nil {
+			logger.GetCommonLogger(utils.ModeName).Errorf("action:revalidateSecret, secretName:%s, %+v", secretName, err)
+		}
+	}()
+}
+
+func (scc *SecretManagerCacheClient) getSecretValue(ctx context.Context, secretName, stage string) (*models.SecretInfo, error) {
 	request := &kms.GetSecretValueRequest{}
 	request.SetSecretName(secretName)
-	request.SetVersionStage(scc.stage)
+	request.SetVersionStage(stage)
 	request.SetFetchExtendedConfig(true)
-	resp, err := scc.secretManagerClient.GetSecretValue(request)
+	resp, err := scc.getSecretValueResponse(ctx, request)
 	if err == nil {
-		return &models.SecretInfo{
-			SecretName:        tea.StringValue(resp.Body.SecretName),
-			VersionId:         tea.StringValue(resp.Body.VersionId),
-			SecretValue:       tea.StringValue(resp.Body.SecretData),
-			SecretDataType:    tea.StringValue(resp.Body.SecretDataType),
-			CreateTime:        tea.StringValue(resp.Body.CreateTime),
-			SecretType:        tea.StringValue(resp.Body.SecretType),
-			AutomaticRotation: tea.StringValue(resp.Body.AutomaticRotation),
-			ExtendedConfig:    tea.StringValue(resp.Body.ExtendedConfig),
-			RotationInterval:  tea.StringValue(resp.Body.RotationInterval),
-			NextRotationDate:  tea.StringValue(resp.Body.NextRotationDate),
-		}, nil
+		scc.recordFetchSuccess(secretName, stage, SecretSourceKMS)
+		return scc.parseSecretInfo(resp)
 	} else {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		logger.GetCommonLogger(utils.ModeName).Errorf("action:getSecretValue", err)
-		if utils.JudgeNeedRecoveryException(err) {
+		scc.recordFetchFailure(secretName, stage, err)
+		if stage == scc.stage && utils.JudgeNeedRecoveryException(err) {
 			secretInfo, inErr := scc.cacheHook.RecoveryGetSecret(secretName)
 			if inErr != nil {
 				logger.GetCommonLogger(utils.ModeName).Errorf("action:recoveryGetSecret", inErr)
@@ -235,100 +827,314 @@ func (scc *SecretManagerCacheClient) getSecretValue(secretName string) (*models.
 			if secretInfo == nil {
 				return nil, err
 			}
+			scc.recordFetchSuccess(secretName, stage, SecretSourceRecovery)
 			return secretInfo, nil
 		}
 	}
 	return nil, err
 }
 
-func (scc *SecretManagerCacheClient) storeAndRefresh(secretName string, secretInfo *models.SecretInfo) error {
-	_, err := scc.refreshNow(secretName, secretInfo)
+// parseSecretInfo 转换KMS返回的凭据信息，二进制凭据在此解码为原始字节
+func (scc *SecretManagerCacheClient) parseSecretInfo(resp *kms.GetSecretValueResponse) (*models.SecretInfo, error) {
+	secretInfo := &models.SecretInfo{
+		SecretName:        tea.StringValue(resp.Body.SecretName),
+		VersionId:         tea.StringValue(resp.Body.VersionId),
+		SecretValue:       tea.StringValue(resp.Body.SecretData),
+		SecretDataType:    tea.StringValue(resp.Body.SecretDataType),
+		CreateTime:        tea.StringValue(resp.Body.CreateTime),
+		SecretType:        tea.StringValue(resp.Body.SecretType),
+		AutomaticRotation: tea.StringValue(resp.Body.AutomaticRotation),
+		ExtendedConfig:    tea.StringValue(resp.Body.ExtendedConfig),
+		RotationInterval:  tea.StringValue(resp.Body.RotationInterval),
+		NextRotationDate:  tea.StringValue(resp.Body.NextRotationDate),
+	}
+	if !scc.legacyBinaryValue && utils.BinaryDataType == secretInfo.SecretDataType {
+		byteBuffer, err := utils.DecodeBinarySecretValue(secretInfo.SecretValue)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("the secret named[%s] binary value decode failed, %v", secretInfo.SecretName, err))
+		}
+		secretInfo.SecretValueByteBuffer = byteBuffer
+	}
+	return secretInfo, nil
+}
+
+// getSecretValueResponse 调用SecretManagerClient获取凭据，未实现SecretManagerContextClient的client在ctx结束后不再等待返回结果
+func (scc *SecretManagerCacheClient) getSecretValueResponse(ctx context.Context, request *kms.GetSecretValueRequest) (*kms.GetSecretValueResponse, error) {
+	if client, ok := scc.secretManagerClient.(service.SecretManagerContextClient); ok {
+		return client.GetSecretValueWithContext(ctx, request)
+	}
+	type result struct {
+		resp *kms.GetSecretValueResponse
+		err  error
+	}
+	resultCh := make(chan *result, 1)
+	go func() {
+		resp, err := scc.secretManagerClient.GetSecretValue(request)
+		resultCh <- &result{resp: resp, err: err}
+	}()
+	select {
+	case r := <-resultCh:
+		return r.resp, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (scc *SecretManagerCacheClient) storeAndRefresh(ctx context.Context, secretName, stage string, secretInfo *models.SecretInfo) error {
+	_, err := scc.refreshNow(ctx, secretName, stage, secretInfo)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (scc *SecretManagerCacheClient) storeAndRefreshLocked(secretName string, secretInfo *models.SecretInfo) error {
-	_, err := scc.refreshNowLocked(secretName, secretInfo)
+func (scc *SecretManagerCacheClient) storeAndRefreshLocked(ctx context.Context, secretName, stage string, secretInfo *models.SecretInfo) error {
+	_, err := scc.refreshNowLocked(ctx, secretName, stage, secretInfo)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (scc *SecretManagerCacheClient) refresh(secretName string, secretInfo *models.SecretInfo) (err error) {
+func (scc *SecretManagerCacheClient) refresh(ctx context.Context, secretName, stage string, secretInfo *models.SecretInfo) (err error) {
+	start := time.Now()
+	key := cache.CacheKey(secretName, stage)
+	evictions := scc.getSecretEvictions(key)
+	defer func() {
+		if err == errSecretEvicted {
+			return
+		}
+		scc.metrics.ObserveRefresh(secretName, time.Since(start), err)
+		if secretInfo != nil && err == nil {
+			scc.recordRefresh(secretName, stage, secretInfo.VersionId, nil)
+		} else if err != nil {
+			scc.recordRefresh(secretName, stage, "", err)
+		}
+	}()
 	if secretInfo == nil {
-		secretInfo, err = scc.getSecretValue(secretName)
+		secretInfo, err = scc.getSecretValue(ctx, secretName, stage)
 		if err != nil {
 			return err
 		}
 	}
-	cacheSecretInfo, err := scc.cacheHook.Put(secretInfo)
+	// 获取凭据值期间凭据被缓存策略淘汰，丢弃刷新结果，避免重新缓存时淘汰其他凭据
+	if scc.getSecretEvictions(key) != evictions {
+		return errSecretEvicted
+	}
+	protectedInfo, err := scc.protectSecretInfo(secretInfo)
 	if err != nil {
 		return err
 	}
-	if cacheSecretInfo != nil {
-		err = scc.cacheSecretStoreStrategy.StoreSecret(cacheSecretInfo)
-		if err != nil {
-			return err
+	cacheSecretInfo, err := scc.cacheHook.Put(protectedInfo)
+	if err != nil || cacheSecretInfo == nil {
+		if protectedInfo.ProtectedValue != secretInfo.ProtectedValue {
+			protectedInfo.ProtectedValue.Destroy()
 		}
+		return err
+	}
+	cacheSecretInfo.Stage = stage
+	// 刷新时间以客户端时钟为准，与判断缓存过期使用同一时钟
+	cacheSecretInfo.RefreshTimestamp = scc.nowMillis()
+	cacheSecretInfo.TTL = scc.refreshSecretStrategy.ParseTTL(secretInfo)
+	cacheSecretInfo.NextRefreshTimestamp = scc.getNextRefreshTime(&models.CacheSecretInfo{
+		SecretInfo:       secretInfo,
+		Stage:            stage,
+		RefreshTimestamp: cacheSecretInfo.RefreshTimestamp,
+	})
+	var oldSecretInfo *models.SecretInfo
+	if oldCacheSecretInfo, inErr := scc.peekCacheSecretInfo(secretName, stage); inErr == nil {
+		oldSecretInfo = oldCacheSecretInfo.SecretInfo
+	}
+	err = scc.cacheSecretStoreStrategy.StoreSecret(cacheSecretInfo)
+	if err == nil && scc.getSecretEvictions(key) != evictions {
+		// 写入缓存期间凭据被缓存策略淘汰，丢弃刷新结果
+		if removable, ok := scc.cacheSecretStoreStrategy.(cache.RemovableSecretCacheStoreStrategy); ok {
+			_ = removable.RemoveCacheSecretInfo(secretName, stage)
+		}
+		err = errSecretEvicted
+	}
+	if err != nil {
+		if protectedInfo.ProtectedValue != secretInfo.ProtectedValue {
+			protectedInfo.ProtectedValue.Destroy()
+		}
+		return err
+	}
+	scc.cachedStageMap.Set(key, struct{}{})
+	scc.registerScrubValues(key, secretInfo)
+	// 被替换的受保护内存在监听器回调完成后清零
+	var release func()
+	if old := scc.swapProtectedValue(key, cacheSecretInfo.SecretInfo.ProtectedValue); old != nil {
+		release = old.Destroy
 	}
-	logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s refresh success", secretName)
+	if stage == scc.stage {
+		scc.notifySecretChange(oldSecretInfo, cacheSecretInfo.SecretInfo, release)
+	} else if release != nil {
+		release()
+	}
+	logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s, stage:%s refresh success", secretName, stage)
 	return nil
 }
 
-func (scc *SecretManagerCacheClient) removeRefreshTask(secretName string) {
-	if v, ok := scc.scheduledMap.Get(secretName); ok {
-		if task, okk := v.(*time.Timer); okk {
-			task.Stop()
-			scc.scheduledMap.Remove(secretName)
+// getNegativeCache 获取未过期的已缓存异常
+func (scc *SecretManagerCacheClient) getNegativeCache(secretName, stage string) error {
+	if scc.negativeCacheTTL <= 0 || scc.negativeCacheMap == nil {
+		return nil
+	}
+	key := cache.CacheKey(secretName, stage)
+	v, ok := scc.negativeCacheMap.Get(key)
+	if !ok {
+		return nil
+	}
+	entry := v.(*negativeCacheEntry)
+	if scc.nowMillis() >= entry.expireTimestamp {
+		scc.negativeCacheMap.RemoveCb(key, func(key string, v interface{}, exists bool) bool {
+			return exists && v == entry
+		})
+		return nil
+	}
+	return entry.err
+}
+
+// putNegativeCache 缓存凭据不存在或无权限等不可重试异常
+func (scc *SecretManagerCacheClient) putNegativeCache(secretName, stage string, err error) {
+	if scc.negativeCacheTTL <= 0 || scc.negativeCacheMap == nil || !utils.JudgeNegativeCacheException(err) {
+		return
+	}
+	key := cache.CacheKey(secretName, stage)
+	if !scc.negativeCacheMap.Has(key) && !scc.reserveNegativeCache() {
+		logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s, stage:%s negative cache is full", secretName, stage)
+		return
+	}
+	scc.negativeCacheMap.Set(key, &negativeCacheEntry{
+		err:             err,
+		expireTimestamp: scc.nowMillis() + scc.negativeCacheTTL,
+	})
+}
+
+// reserveNegativeCache 判断是否可以缓存新的异常，达到数量上限时清理已过期的异常
+func (scc *SecretManagerCacheClient) reserveNegativeCache() bool {
+	maxEntries := scc.maxNegativeCacheEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxNegativeCacheEntries
+	}
+	if scc.negativeCacheMap.Count() < maxEntries {
+		return true
+	}
+	scc.negativeCacheMtx.Lock()
+	defer scc.negativeCacheMtx.Unlock()
+	now := scc.nowMillis()
+	if now < scc.negativeCacheNextSweep {
+		return false
+	}
+	var nextSweep int64
+	for item := range scc.negativeCacheMap.IterBuffered() {
+		entry := item.Val.(*negativeCacheEntry)
+		if now >= entry.expireTimestamp {
+			scc.negativeCacheMap.RemoveCb(item.Key, func(key string, v interface{}, exists bool) bool {
+				return exists && v == entry
+			})
+		} else if nextSweep == 0 || entry.expireTimestamp < nextSweep {
+			nextSweep = entry.expireTimestamp
+		}
+	}
+	scc.negativeCacheNextSweep = nextSweep
+	return scc.negativeCacheMap.Count() < maxEntries
+}
+
+func (scc *SecretManagerCacheClient) removeNegativeCache(secretName, stage string) {
+	if scc.negativeCacheMap != nil {
+		scc.negativeCacheMap.Remove(cache.CacheKey(secretName, stage))
+	}
+}
+
+// removeAllNegativeCache 删除凭据所有Version Stage的已缓存异常
+func (scc *SecretManagerCacheClient) removeAllNegativeCache(secretName string) {
+	if scc.negativeCacheMap == nil {
+		return
+	}
+	for _, key := range scc.negativeCacheMap.Keys() {
+		if name, _ := cache.ParseCacheKey(key); name == secretName {
+			scc.negativeCacheMap.Remove(key)
 		}
 	}
 }
 
-func (scc *SecretManagerCacheClient) addRefreshTask(secretName string, runnable runnable) error {
-	cacheSecretInfo, err := scc.cacheSecretStoreStrategy.GetCacheSecretInfo(secretName)
+func (scc *SecretManagerCacheClient) removeAllRefreshTasks() {
+	for _, key := range scc.scheduledMap.Keys() {
+		scc.removeRefreshTask(key)
+	}
+}
+
+func (scc *SecretManagerCacheClient) removeRefreshTask(key string) {
+	if scc.scheduledMap.Has(key) {
+		if scc.scheduler != nil {
+			scc.scheduler.cancel(key)
+		}
+		scc.scheduledMap.Remove(key)
+	}
+}
+
+func (scc *SecretManagerCacheClient) addRefreshTask(secretName, stage string, runnable runnable) error {
+	if scc.isClosed() {
+		return nil
+	}
+	cacheSecretInfo, err := scc.peekCacheSecretInfo(secretName, stage)
 	if err != nil {
 		return err
 	}
+	now := scc.nowMillis()
+	executeTime := cacheSecretInfo.NextRefreshTimestamp
+	if executeTime <= now {
+		// 未记录下一次刷新时间或刷新失败后重新添加任务时，从当前时间起按缓存TTL计算，避免立即重试
+		executeTime = scc.refreshSecretStrategy.GetNextExecuteTime(secretName, scc.getCacheTTL(cacheSecretInfo), now)
+	}
+	if scc.scheduler == nil {
+		return errors.New(fmt.Sprintf("secretCacheClient is not initialized"))
+	}
+	key := cache.CacheKey(secretName, stage)
+	scc.scheduledMap.Set(key, executeTime)
+	scc.scheduler.schedule(key, secretName, stage, executeTime, runnable.getRunnable())
+	logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s, stage:%s addRefreshTask success", secretName, stage)
+	return nil
+}
+
+// getNextRefreshTime 根据刷新策略计算凭据下一次刷新的时间戳，cacheSecretInfo须包含明文凭据值
+func (scc *SecretManagerCacheClient) getNextRefreshTime(cacheSecretInfo *models.CacheSecretInfo) int64 {
 	executeTime := scc.refreshSecretStrategy.ParseNextExecuteTime(cacheSecretInfo)
 	if executeTime <= 0 {
-		refreshTimestamp := cacheSecretInfo.RefreshTimestamp
 		ttl := defaultTtl
-		if t, ok := scc.secretTTLMap[secretName]; ok {
+		if t, ok := scc.getSecretTTL(cacheSecretInfo.SecretInfo.SecretName); ok {
 			ttl = t
 		}
-		executeTime = scc.refreshSecretStrategy.GetNextExecuteTime(secretName, ttl, refreshTimestamp)
-		if executeTime < (time.Now().UnixNano() / 1e6) {
-			executeTime = time.Now().UnixNano() / 1e6
-		}
+		executeTime = scc.refreshSecretStrategy.GetNextExecuteTime(cacheSecretInfo.SecretInfo.SecretName, ttl, cacheSecretInfo.RefreshTimestamp)
 	}
-	delay := executeTime - time.Now().UnixNano()/1e6
-	if delay < 0 {
-		delay = 0
-	}
-	schedule := time.AfterFunc(time.Duration(delay)*time.Millisecond, runnable.getRunnable())
-	scc.scheduledMap.Set(secretName, schedule)
-	logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s addRefreshTask success", secretName)
-	return nil
+	return executeTime
 }
 
-func (scc *SecretManagerCacheClient) refreshNow(secretName string, secretInfo *models.SecretInfo) (bool, error) {
-	lck := scc.getLock(secretName)
+func (scc *SecretManagerCacheClient) refreshNow(ctx context.Context, secretName, stage string, secretInfo *models.SecretInfo) (bool, error) {
+	if !scc.beginRefresh() {
+		return false, ErrClientClosed
+	}
+	defer scc.refreshWg.Done()
+	key := cache.CacheKey(secretName, stage)
+	lck := scc.getLock(key)
 	lck.Lock()
-	defer lck.Unlock()
-	return scc.refreshNowLocked(secretName, secretInfo)
+	defer scc.releaseLock(key, lck)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return scc.refreshNowLocked(ctx, secretName, stage, secretInfo)
 }
 
-func (scc *SecretManagerCacheClient) refreshNowLocked(secretName string, secretInfo *models.SecretInfo) (bool, error) {
-	err := scc.refresh(secretName, secretInfo)
+func (scc *SecretManagerCacheClient) refreshNowLocked(ctx context.Context, secretName, stage string, secretInfo *models.SecretInfo) (bool, error) {
+	err := scc.refresh(ctx, secretName, stage, secretInfo)
 	if err != nil {
 		return false, err
 	}
-	scc.removeRefreshTask(secretName)
-	err = scc.addRefreshTask(secretName, &refreshSecretTask{
+	scc.removeRefreshTask(cache.CacheKey(secretName, stage))
+	err = scc.addRefreshTask(secretName, stage, &refreshSecretTask{
 		secretName: secretName,
+		stage:      stage,
 		client:     scc,
 	})
 	if err != nil {
@@ -355,25 +1161,176 @@ func (scc *SecretManagerCacheClient) judgeSkipRefreshException(err error) bool {
 	}(err)
 }
 
+// getCacheSecretInfo 获取指定Version Stage的缓存凭据信息，不支持多Version Stage的缓存策略只能获取默认Version Stage
+func (scc *SecretManagerCacheClient) getCacheSecretInfo(secretName, stage string) (*models.CacheSecretInfo, error) {
+	if stageStrategy, ok := scc.cacheSecretStoreStrategy.(cache.StageSecretCacheStoreStrategy); ok {
+		return stageStrategy.GetCacheSecretInfoByStage(secretName, stage)
+	}
+	if stage != scc.stage {
+		return nil, errors.New(fmt.Sprintf("the cache secret strategy do not support stage[%s]", stage))
+	}
+	return scc.cacheSecretStoreStrategy.GetCacheSecretInfo(secretName)
+}
+
+// peekCacheSecretInfo 获取缓存凭据信息，支持淘汰的缓存策略不会因此更新凭据访问时间
+func (scc *SecretManagerCacheClient) peekCacheSecretInfo(secretName, stage string) (*models.CacheSecretInfo, error) {
+	if evictable, ok := scc.cacheSecretStoreStrategy.(cache.EvictableSecretCacheStoreStrategy); ok {
+		return evictable.PeekCacheSecretInfo(secretName, stage)
+	}
+	return scc.getCacheSecretInfo(secretName, stage)
+}
+
+// getCachedStages 获取凭据已缓存或已添加定时刷新的Version Stage列表
+func (scc *SecretManagerCacheClient) getCachedStages(secretName string) []string {
+	stages := []string{scc.stage}
+	stageSet := map[string]struct{}{cache.CacheKey(secretName, scc.stage): {}}
+	for _, key := range append(scc.cachedStageMap.Keys(), scc.scheduledMap.Keys()...) {
+		name, stage := cache.ParseCacheKey(key)
+		if _, ok := stageSet[key]; ok || name != secretName {
+			continue
+		}
+		stageSet[key] = struct{}{}
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
+func (scc *SecretManagerCacheClient) removeSecret(secretName, stage string) error {
+	key := cache.CacheKey(secretName, stage)
+	lck := scc.getLock(key)
+	lck.Lock()
+	defer scc.releaseLock(key, lck)
+	scc.removeRefreshTask(key)
+	scc.cachedStageMap.Remove(key)
+	scc.parsedValueMap.Remove(key)
+	scc.removeSecretStatus(secretName, stage)
+	defer scc.destroyProtectedValue(key)
+	defer scc.unregisterScrubValues(key)
+	if removable, ok := scc.cacheSecretStoreStrategy.(cache.RemovableSecretCacheStoreStrategy); ok {
+		return removable.RemoveCacheSecretInfo(secretName, stage)
+	}
+	return nil
+}
+
+// onSecretEvicted 凭据被缓存策略淘汰后停止刷新任务并释放相关资源
+// 回调时可能持有其他凭据的锁，因此在协程中获取凭据锁后再释放资源，正在进行的刷新会丢弃刷新结果
+func (scc *SecretManagerCacheClient) onSecretEvicted(secretName, stage string) {
+	key := cache.CacheKey(secretName, stage)
+	scc.markSecretEvicted(key)
+	if !scc.beginRefresh() {
+		return
+	}
+	go func() {
+		defer scc.refreshWg.Done()
+		lck := scc.getLock(key)
+		lck.Lock()
+		defer scc.releaseLock(key, lck)
+		if _, err := scc.peekCacheSecretInfo(secretName, stage); err == nil {
+			// 淘汰后凭据已被重新加载
+			return
+		}
+		scc.removeRefreshTask(key)
+		scc.cachedStageMap.Remove(key)
+		scc.parsedValueMap.Remove(key)
+		scc.removeSecretStatus(secretName, stage)
+		scc.destroyProtectedValue(key)
+		scc.unregisterScrubValues(key)
+		scc.removeVersionCache(secretName)
+		logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s, stage:%s evicted from cache", secretName, stage)
+	}()
+}
+
+// getLock 获取凭据锁并增加引用计数，使用完成后必须调用releaseLock
 func (scc *SecretManagerCacheClient) getLock(key string) *sync.Mutex {
 	scc.secretNameMtx.Lock()
 	defer scc.secretNameMtx.Unlock()
+	if scc.secretNameMtxMap == nil {
+		scc.secretNameMtxMap = make(map[string]*sync.Mutex)
+	}
+	if scc.secretLockStateMap == nil {
+		scc.secretLockStateMap = make(map[string]*secretLockState)
+	}
+	state, ok := scc.secretLockStateMap[key]
+	if !ok {
+		state = &secretLockState{}
+		scc.secretLockStateMap[key] = state
+	}
+	state.refs++
 	mtx, ok := scc.secretNameMtxMap[key]
-	if ok {
-		return mtx
+	if !ok {
+		mtx = &sync.Mutex{}
+		scc.secretNameMtxMap[key] = mtx
+	}
+	return mtx
+}
+
+// releaseLock 释放凭据锁，没有协程持有或等待时删除该锁
+func (scc *SecretManagerCacheClient) releaseLock(key string, mtx *sync.Mutex) {
+	mtx.Unlock()
+	scc.secretNameMtx.Lock()
+	defer scc.secretNameMtx.Unlock()
+	state, ok := scc.secretLockStateMap[key]
+	if !ok {
+		return
+	}
+	state.refs--
+	if state.refs <= 0 {
+		delete(scc.secretLockStateMap, key)
+		delete(scc.secretNameMtxMap, key)
+	}
+}
+
+// getSecretEvictions 获取凭据锁被持有期间凭据被淘汰的次数
+func (scc *SecretManagerCacheClient) getSecretEvictions(key string) int {
+	scc.secretNameMtx.Lock()
+	defer scc.secretNameMtx.Unlock()
+	if state, ok := scc.secretLockStateMap[key]; ok {
+		return state.evictions
+	}
+	return 0
+}
+
+// markSecretEvicted 记录凭据被淘汰，持有凭据锁的刷新据此丢弃刷新结果
+func (scc *SecretManagerCacheClient) markSecretEvicted(key string) {
+	scc.secretNameMtx.Lock()
+	defer scc.secretNameMtx.Unlock()
+	if state, ok := scc.secretLockStateMap[key]; ok {
+		state.evictions++
 	}
-	scc.secretNameMtxMap[key] = &sync.Mutex{}
-	return scc.secretNameMtxMap[key]
 }
 
 func (rst *refreshSecretTask) getRunnable() func() {
 	return func() {
-		err := rst.client.refresh(rst.secretName, nil)
+		if !rst.client.beginRefresh() {
+			return
+		}
+		defer rst.client.refreshWg.Done()
+		key := cache.CacheKey(rst.secretName, rst.stage)
+		lck := rst.client.getLock(key)
+		lck.Lock()
+		defer rst.client.releaseLock(key, lck)
+		if _, err := rst.client.peekCacheSecretInfo(rst.secretName, rst.stage); err != nil {
+			logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s, stage:%s not in cache, stop refreshSecretTask", rst.secretName, rst.stage)
+			rst.client.removeRefreshTask(key)
+			return
+		}
+		ctx, span := rst.client.getTracer().Start(rst.client.getBackgroundContext(), tracing.SpanRefreshSecret)
+		span.SetAttribute(tracing.AttributeSecretName, rst.secretName)
+		span.SetAttribute(tracing.AttributeStage, rst.stage)
+		err := rst.client.refresh(ctx, rst.secretName, rst.stage, nil)
+		if err == errSecretEvicted {
+			span.End()
+			logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s, stage:%s evicted during refresh, stop refreshSecretTask", rst.secretName, rst.stage)
+			rst.client.removeRefreshTask(key)
+			return
+		}
 		if err != nil {
+			span.RecordError(err)
 			logger.GetCommonLogger(utils.ModeName).Errorf("action:refreshSecretTask", err)
 		}
-		rst.client.removeRefreshTask(rst.secretName)
-		err = rst.client.addRefreshTask(rst.secretName, rst)
+		span.End()
+		rst.client.removeRefreshTask(key)
+		err = rst.client.addRefreshTask(rst.secretName, rst.stage, rst)
 		if err != nil {
 			logger.GetCommonLogger(utils.ModeName).Errorf("action:addRefreshTask", err)
 		}
@@ -6,12 +6,16 @@ import (
 
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/metrics"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/service"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/tracing"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
 )
 
 type SecretCacheClientBuilder struct {
 	secretCacheClient *SecretManagerCacheClient
+	// 根据TTL字段名称构建刷新策略，在Build时执行，与WithParseJSONTTL的调用顺序无关
+	refreshStrategyFactory func(jsonTTLPropertyName string) service.RefreshSecretStrategy
 }
 
 // NewClient 构建一个Secret Cache client
@@ -46,6 +50,27 @@ func (scb *SecretCacheClientBuilder) WithParseJSONTTL(jsonTTLPropertyName string
 func (scb *SecretCacheClientBuilder) WithRefreshSecretStrategy(refreshSecretStrategy service.RefreshSecretStrategy) *SecretCacheClientBuilder {
 	scb.buildSecretCacheClient()
 	scb.secretCacheClient.refreshSecretStrategy = refreshSecretStrategy
+	scb.refreshStrategyFactory = nil
+	return scb
+}
+
+// WithRefreshJitter 使用带随机抖动的刷新策略，在TTL的refreshAheadFactor比例处提前刷新并叠加jitterFactor比例的随机延后
+func (scb *SecretCacheClientBuilder) WithRefreshJitter(refreshAheadFactor, jitterFactor float64) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.refreshSecretStrategy = nil
+	scb.refreshStrategyFactory = func(jsonTTLPropertyName string) service.RefreshSecretStrategy {
+		return service.NewJitterRefreshSecretStrategy(jsonTTLPropertyName, refreshAheadFactor, jitterFactor)
+	}
+	return scb
+}
+
+// WithRotationRefresh 使用感知凭据轮转时间的刷新策略，在NextRotationDate之后的gracePeriod刷新，并最多间隔fallbackInterval轮询一次，单位MS
+func (scb *SecretCacheClientBuilder) WithRotationRefresh(gracePeriod, fallbackInterval int64) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.refreshSecretStrategy = nil
+	scb.refreshStrategyFactory = func(jsonTTLPropertyName string) service.RefreshSecretStrategy {
+		return service.NewRotationRefreshSecretStrategy(jsonTTLPropertyName, gracePeriod, fallbackInterval)
+	}
 	return scb
 }
 
@@ -56,6 +81,72 @@ func (scb *SecretCacheClientBuilder) WithCacheSecretStrategy(cacheSecretStrategy
 	return scb
 }
 
+// WithStaleWhileRevalidate 开启凭据过期后返回旧值并后台刷新，maxStaleness为过期后允许返回旧值的最大时长，单位MS
+func (scb *SecretCacheClientBuilder) WithStaleWhileRevalidate(maxStaleness int64) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.maxStaleness = maxStaleness
+	return scb
+}
+
+// WithPreloadConcurrency 设定初始化加载及批量获取凭据的最大并发数
+func (scb *SecretCacheClientBuilder) WithPreloadConcurrency(concurrency int) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.preloadConcurrency = concurrency
+	return scb
+}
+
+// WithRefreshWorkers 设定执行定时刷新的最大并发数
+func (scb *SecretCacheClientBuilder) WithRefreshWorkers(workers int) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.refreshWorkers = workers
+	return scb
+}
+
+// WithNegativeCacheTTL 设定凭据不存在或无权限等不可重试异常的缓存时长，单位MS，缓存期间直接返回原异常
+func (scb *SecretCacheClientBuilder) WithNegativeCacheTTL(ttl int64) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.negativeCacheTTL = ttl
+	return scb
+}
+
+// WithInitFailurePolicy 指定初始化时预加载凭据失败的处理策略，默认为InitFailFast
+func (scb *SecretCacheClientBuilder) WithInitFailurePolicy(policy InitFailurePolicy) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.initFailurePolicy = policy
+	return scb
+}
+
+// WithRequiredSecrets 指定初始化时必须加载成功的凭据，并使用InitRequireListed策略
+func (scb *SecretCacheClientBuilder) WithRequiredSecrets(secretNames ...string) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.initFailurePolicy = InitRequireListed
+	scb.secretCacheClient.requiredSecrets = append(scb.secretCacheClient.requiredSecrets, secretNames...)
+	return scb
+}
+
+// WithProtectedValues 开启受保护凭据值，凭据值存放于mlock锁定的内存并在替换及淘汰时清零，Linux下同时将进程标记为不可dump
+// 开启后建议通过UseSecretValue访问凭据值，GetSecretInfo等接口返回的明文副本不受保护，且不支持FileCacheSecretStoreStrategy
+func (scb *SecretCacheClientBuilder) WithProtectedValues() *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.protectedValues = true
+	return scb
+}
+
+// WithLogScrubbing 开启日志脱敏，通过CommonLogger输出的日志中已缓存的凭据值及JSON凭据的字符串字段值将被替换为******
+// 脱敏需要在内存中保留凭据值的副本，与WithProtectedValues同时开启时会削弱受保护凭据值的效果
+func (scb *SecretCacheClientBuilder) WithLogScrubbing() *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.logScrubbing = true
+	return scb
+}
+
+// WithLegacyBinaryValue 二进制凭据保持旧行为，GetBinaryValue返回KMS返回的base64文本的字节而非解码后的原始字节
+func (scb *SecretCacheClientBuilder) WithLegacyBinaryValue() *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.legacyBinaryValue = true
+	return scb
+}
+
 // WithCacheStage 指定凭据Version stage
 func (scb *SecretCacheClientBuilder) WithCacheStage(stage string) *SecretCacheClientBuilder {
 	scb.buildSecretCacheClient()
@@ -70,6 +161,34 @@ func (scb *SecretCacheClientBuilder) WithSecretCacheHook(hook cache.SecretCacheH
 	return scb
 }
 
+// WithSecretChangeListener 注册指定凭据名称的变更监听器
+func (scb *SecretCacheClientBuilder) WithSecretChangeListener(secretName string, listener SecretChangeListener) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.AddSecretChangeListener(secretName, listener)
+	return scb
+}
+
+// WithGlobalSecretChangeListener 注册所有凭据的变更监听器
+func (scb *SecretCacheClientBuilder) WithGlobalSecretChangeListener(listener SecretChangeListener) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.AddGlobalSecretChangeListener(listener)
+	return scb
+}
+
+// WithMetrics 指定指标采集，未指定Secret Manager Client时同时用于默认Client的KMS调用指标
+func (scb *SecretCacheClientBuilder) WithMetrics(metrics metrics.Metrics) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.metrics = metrics
+	return scb
+}
+
+// WithTracer 指定链路追踪，未指定Secret Manager Client时同时用于默认Client的KMS调用追踪
+func (scb *SecretCacheClientBuilder) WithTracer(tracer tracing.Tracer) *SecretCacheClientBuilder {
+	scb.buildSecretCacheClient()
+	scb.secretCacheClient.tracer = tracer
+	return scb
+}
+
 // WithLogger 指定输出日志
 func (scb *SecretCacheClientBuilder) WithLogger(l logger.Wrapper) *SecretCacheClientBuilder {
 	err := logger.RegisterLogger(utils.ModeName, l)
@@ -88,6 +207,9 @@ func (scb *SecretCacheClientBuilder) Build() (*SecretManagerCacheClient, error)
 		}
 	}
 	scb.buildSecretCacheClient()
+	if scb.refreshStrategyFactory != nil {
+		scb.secretCacheClient.refreshSecretStrategy = scb.refreshStrategyFactory(scb.secretCacheClient.jsonTTLPropertyName)
+	}
 	err := scb.secretCacheClient.Init()
 	if err != nil {
 		return nil, err
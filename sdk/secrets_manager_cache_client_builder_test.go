@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/service"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
 
@@ -45,3 +46,18 @@ func TestNewSecretCacheClientBuilder(t *testing.T) {
 	assert.Nil(t, err)
 	println("secretInfo:", secretInfo.SecretValue)
 }
+
+func TestSecretCacheClientBuilder_RefreshStrategyOrder(t *testing.T) {
+	secretInfo := &models.SecretInfo{SecretValue: `{"ttl":5000}`}
+	for _, builder := range []*SecretCacheClientBuilder{
+		NewSecretCacheClientBuilder(newMockSecretManagerClient()).WithRefreshJitter(0.8, 0.1).WithParseJSONTTL(""),
+		NewSecretCacheClientBuilder(newMockSecretManagerClient()).WithParseJSONTTL("").WithRefreshJitter(0.8, 0.1),
+		NewSecretCacheClientBuilder(newMockSecretManagerClient()).WithRotationRefresh(1000, 60*1000).WithParseJSONTTL(""),
+		NewSecretCacheClientBuilder(newMockSecretManagerClient()).WithParseJSONTTL("").WithRotationRefresh(1000, 60*1000),
+	} {
+		client, err := builder.Build()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(-1), client.refreshSecretStrategy.ParseTTL(secretInfo))
+		assert.Nil(t, client.Close())
+	}
+}
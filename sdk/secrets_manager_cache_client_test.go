@@ -1,15 +1,28 @@
 package sdk
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	kms "github.com/alibabacloud-go/kms-20160120/v3/client"
+	"github.com/alibabacloud-go/tea/tea"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/cache"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/metrics"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/service"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/tracing"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
 
 	cmap "github.com/orcaman/concurrent-map"
@@ -112,3 +125,997 @@ func TestSecretCacheClient_RefreshNow(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// mockSecretManagerClient 模拟的SecretManagerClient，按凭据名称返回预置的凭据值
+type mockSecretManagerClient struct {
+	mtx     sync.Mutex
+	secrets map[string]*kms.GetSecretValueResponseBody
+	errs    map[string]error
+	delay   time.Duration
+	calls   int32
+	// 获取凭据值时回调，用于在测试中控制请求时序
+	fetchHook func(secretName string)
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+func newMockSecretManagerClient() *mockSecretManagerClient {
+	return &mockSecretManagerClient{
+		secrets: make(map[string]*kms.GetSecretValueResponseBody),
+		errs:    make(map[string]error),
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (m *mockSecretManagerClient) putSecret(secretName, versionId, secretData string) {
+	m.putStageSecret(secretName, utils.StageAcsCurrent, versionId, secretData)
+}
+
+func (m *mockSecretManagerClient) putStageSecret(secretName, stage, versionId, secretData string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.secrets[cache.CacheKey(secretName, stage)] = &kms.GetSecretValueResponseBody{
+		SecretName:     tea.String(secretName),
+		VersionId:      tea.String(versionId),
+		SecretData:     tea.String(secretData),
+		SecretDataType: tea.String(utils.TextDataType),
+		SecretType:     tea.String("Generic"),
+	}
+}
+
+func (m *mockSecretManagerClient) putVersionSecret(secretName, versionId, secretData string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.secrets[versionCacheKey(secretName, versionId)] = &kms.GetSecretValueResponseBody{
+		SecretName:     tea.String(secretName),
+		VersionId:      tea.String(versionId),
+		SecretData:     tea.String(secretData),
+		SecretDataType: tea.String(utils.TextDataType),
+		SecretType:     tea.String("Generic"),
+	}
+}
+
+func (m *mockSecretManagerClient) setSecretType(secretName, stage, secretType string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.secrets[cache.CacheKey(secretName, stage)].SecretType = tea.String(secretType)
+}
+
+func (m *mockSecretManagerClient) putBinarySecret(secretName, versionId string, secretData []byte) {
+	m.putSecret(secretName, versionId, base64.StdEncoding.EncodeToString(secretData))
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.secrets[cache.CacheKey(secretName, utils.StageAcsCurrent)].SecretDataType = tea.String(utils.BinaryDataType)
+}
+
+func (m *mockSecretManagerClient) putError(secretName string, err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.errs[secretName] = err
+}
+
+func (m *mockSecretManagerClient) setFetchHook(hook func(secretName string)) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.fetchHook = hook
+}
+
+func (m *mockSecretManagerClient) removeError(secretName string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.errs, secretName)
+}
+
+func (m *mockSecretManagerClient) Init() error {
+	return nil
+}
+
+func (m *mockSecretManagerClient) GetSecretValue(req *kms.GetSecretValueRequest) (*kms.GetSecretValueResponse, error) {
+	atomic.AddInt32(&m.calls, 1)
+	if m.delay > 0 {
+		time.Sleep(m.delay)
+	}
+	m.mtx.Lock()
+	hook := m.fetchHook
+	m.mtx.Unlock()
+	if hook != nil {
+		hook(tea.StringValue(req.SecretName))
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	secretName := tea.StringValue(req.SecretName)
+	if err, ok := m.errs[secretName]; ok {
+		return nil, err
+	}
+	key := cache.CacheKey(secretName, tea.StringValue(req.VersionStage))
+	if req.VersionId != nil {
+		key = versionCacheKey(secretName, tea.StringValue(req.VersionId))
+	}
+	body, ok := m.secrets[key]
+	if !ok {
+		return nil, &tea.SDKError{Code: tea.String("Forbidden.ResourceNotFound"), Message: tea.String("secret not found")}
+	}
+	copied := *body
+	return &kms.GetSecretValueResponse{Body: &copied}, nil
+}
+
+func (m *mockSecretManagerClient) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+	})
+	return nil
+}
+
+func (m *mockSecretManagerClient) isClosed() bool {
+	select {
+	case <-m.closeCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func newMockCacheClient(smc service.SecretManagerClient) *SecretManagerCacheClient {
+	_ = logger.RegisterLogger(utils.ModeName, logger.NewDefaultLogger(log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile)))
+	client := NewSecretCacheClient()
+	client.secretManagerClient = smc
+	return client
+}
+
+func TestSecretCacheClient_GetSecretInfoWithContext(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("mock_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	info, err := client.GetSecretInfoWithContext(context.Background(), "mock_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", info.SecretValue)
+
+	smc.delay = 2 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.RefreshNowWithContext(ctx, "mock_secret")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(start) < time.Second)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.GetStringValueWithContext(canceled, "other_secret")
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+// fakeClock 测试用时钟，仅在调用Advance时前进
+type fakeClock struct {
+	mtx sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (fc *fakeClock) Now() time.Time {
+	fc.mtx.Lock()
+	defer fc.mtx.Unlock()
+	return fc.now
+}
+
+func (fc *fakeClock) Advance(d time.Duration) {
+	fc.mtx.Lock()
+	defer fc.mtx.Unlock()
+	fc.now = fc.now.Add(d)
+}
+
+func TestSecretCacheClient_StaleWhileRevalidate(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("stale_secret", "v1", "value1")
+	clock := newFakeClock()
+	client := newMockCacheClient(smc)
+	client.clock = clock.Now
+	client.secretTTLMap["stale_secret"] = 60 * 1000
+	client.maxStaleness = 5 * 60 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	smc.putError("stale_secret", errors.New("kms unreachable"))
+	clock.Advance(2 * time.Minute)
+	value, err := client.GetStringValue("stale_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", value)
+
+	clock.Advance(5 * time.Minute)
+	_, err = client.GetStringValue("stale_secret")
+	assert.NotNil(t, err)
+
+	smc.removeError("stale_secret")
+	smc.putSecret("stale_secret", "v2", "value2")
+	value, err = client.GetStringValue("stale_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value2", value)
+}
+
+func TestSecretCacheClient_ClockRefresh(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("clock_secret", "v1", "value1")
+	clock := newFakeClock()
+	client := newMockCacheClient(smc)
+	client.clock = clock.Now
+	client.secretTTLMap["clock_secret"] = 60 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	// 刷新时间及定时刷新均使用客户端时钟
+	cacheSecretInfo, err := client.peekCacheSecretInfo("clock_secret", utils.StageAcsCurrent)
+	assert.Nil(t, err)
+	assert.Equal(t, clock.Now().UnixNano()/1e6, cacheSecretInfo.RefreshTimestamp)
+	calls := atomic.LoadInt32(&smc.calls)
+
+	smc.putSecret("clock_secret", "v2", "value2")
+	clock.Advance(2 * time.Minute)
+	client.scheduler.wake()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && atomic.LoadInt32(&smc.calls) == calls {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, calls+1, atomic.LoadInt32(&smc.calls))
+	client.refreshWg.Wait()
+	value, err := client.GetStringValue("clock_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value2", value)
+}
+
+func TestSecretCacheClient_RotationRefreshExpire(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("rotation_secret", "v1", "value1")
+	smc.mtx.Lock()
+	body := smc.secrets["rotation_secret"]
+	body.AutomaticRotation = tea.String(service.AutomaticRotationEnabled)
+	body.NextRotationDate = tea.String(time.Now().Add(3 * time.Hour).UTC().Format(time.RFC3339))
+	smc.mtx.Unlock()
+	clock := newFakeClock()
+	client := newMockCacheClient(smc)
+	client.clock = clock.Now
+	client.refreshSecretStrategy = service.NewRotationRefreshSecretStrategy("", 0, 24*60*60*1000)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("rotation_secret")
+	assert.Nil(t, err)
+	calls := atomic.LoadInt32(&smc.calls)
+
+	// 超过默认TTL但未到轮转刷新时间，缓存仍然有效
+	clock.Advance(2 * time.Hour)
+	_, err = client.GetSecretInfo("rotation_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, calls, atomic.LoadInt32(&smc.calls))
+
+	clock.Advance(2 * time.Hour)
+	_, err = client.GetSecretInfo("rotation_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, calls+1, atomic.LoadInt32(&smc.calls))
+}
+
+func TestSecretCacheClient_RefreshFailureBackoff(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("failing_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	client.secretTTLMap["failing_secret"] = 100
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	// 刷新失败后按TTL重新调度，不会立即重试
+	smc.putError("failing_secret", errors.New("kms unreachable"))
+	calls := atomic.LoadInt32(&smc.calls)
+	time.Sleep(500 * time.Millisecond)
+	assert.True(t, atomic.LoadInt32(&smc.calls)-calls <= 10)
+}
+
+func TestSecretCacheClient_JSONTTLExpire(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("json_ttl_secret", "v1", `{"ttl":60000,"password":"value1"}`)
+	clock := newFakeClock()
+	client := newMockCacheClient(smc)
+	client.clock = clock.Now
+	client.protectedValues = true
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("json_ttl_secret")
+	assert.Nil(t, err)
+	cacheSecretInfo, err := client.peekCacheSecretInfo("json_ttl_secret", utils.StageAcsCurrent)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(60*1000), cacheSecretInfo.TTL)
+	assert.Equal(t, "", cacheSecretInfo.SecretInfo.SecretValue)
+	calls := atomic.LoadInt32(&smc.calls)
+
+	// 凭据值中的TTL在获取时解析，缓存中的受保护副本无需明文即可判断过期
+	clock.Advance(2 * time.Minute)
+	_, err = client.GetSecretInfo("json_ttl_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, calls+1, atomic.LoadInt32(&smc.calls))
+}
+
+func TestSecretCacheClient_BoundedMemoryCache(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("lru_secret_1", "v1", "value1")
+	smc.putSecret("lru_secret_2", "v1", "value2")
+	smc.putSecret("lru_secret_3", "v1", "value3")
+	client := newMockCacheClient(smc)
+	client.cacheSecretStoreStrategy = cache.NewBoundedMemoryCacheSecretStoreStrategy(2, 0)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("lru_secret_1")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfo("lru_secret_2")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfo("lru_secret_1")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfo("lru_secret_3")
+	assert.Nil(t, err)
+	// 等待淘汰凭据的资源释放完成
+	client.refreshWg.Wait()
+
+	assert.False(t, client.scheduledMap.Has("lru_secret_2"))
+	assert.True(t, client.scheduledMap.Has("lru_secret_1"))
+	assert.True(t, client.scheduledMap.Has("lru_secret_3"))
+
+	calls := atomic.LoadInt32(&smc.calls)
+	_, err = client.GetSecretInfo("lru_secret_2")
+	assert.Nil(t, err)
+	assert.Equal(t, calls+1, atomic.LoadInt32(&smc.calls))
+}
+
+func TestSecretCacheClient_IdleEviction(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("idle_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	clock := newFakeClock()
+	storeStrategy := cache.NewBoundedMemoryCacheSecretStoreStrategy(0, 60*1000)
+	storeStrategy.Clock = clock.Now
+	client.cacheSecretStoreStrategy = storeStrategy
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("idle_secret")
+	assert.Nil(t, err)
+	assert.True(t, client.scheduledMap.Has("idle_secret"))
+
+	clock.Advance(2 * time.Minute)
+	calls := atomic.LoadInt32(&smc.calls)
+	_, err = client.GetSecretInfo("idle_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, calls+1, atomic.LoadInt32(&smc.calls))
+}
+
+func TestSecretCacheClient_EvictDuringRefresh(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("evict_secret_1", "v1", "value1")
+	smc.putSecret("evict_secret_2", "v1", "value2")
+	client := newMockCacheClient(smc)
+	client.cacheSecretStoreStrategy = cache.NewBoundedMemoryCacheSecretStoreStrategy(1, 0)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("evict_secret_1")
+	assert.Nil(t, err)
+
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	smc.setFetchHook(func(secretName string) {
+		if secretName == "evict_secret_1" {
+			once.Do(func() {
+				close(fetching)
+				<-release
+			})
+		}
+	})
+	refreshed := make(chan error, 1)
+	go func() {
+		_, err := client.RefreshNow("evict_secret_1")
+		refreshed <- err
+	}()
+	<-fetching
+
+	// 刷新持有凭据锁期间，缓存其他凭据导致该凭据被淘汰
+	_, err = client.GetSecretInfo("evict_secret_2")
+	assert.Nil(t, err)
+	smc.putSecret("evict_secret_1", "v2", "value1-v2")
+	close(release)
+	assert.Equal(t, errSecretEvicted, <-refreshed)
+	client.refreshWg.Wait()
+
+	_, err = client.peekCacheSecretInfo("evict_secret_1", utils.StageAcsCurrent)
+	assert.NotNil(t, err)
+	assert.False(t, client.scheduledMap.Has("evict_secret_1"))
+	assert.True(t, client.scheduledMap.Has("evict_secret_2"))
+	client.secretNameMtx.Lock()
+	assert.Empty(t, client.secretNameMtxMap)
+	assert.Empty(t, client.secretLockStateMap)
+	client.secretNameMtx.Unlock()
+
+	value, err := client.GetStringValue("evict_secret_1")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1-v2", value)
+}
+
+func TestSecretCacheClient_AddAndRemoveSecret(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("watched_secret_1", "v1", "value1")
+	smc.putSecret("watched_secret_2", "v1", "value2")
+	client := newMockCacheClient(smc)
+	client.secretTTLMap["watched_secret_1"] = 60 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+	assert.Equal(t, []string{"watched_secret_1"}, client.ListSecrets())
+
+	assert.NotNil(t, client.AddSecret("missing_secret", 60*1000))
+	assert.NotNil(t, client.AddSecret("watched_secret_2", 0))
+	assert.Nil(t, client.AddSecret("watched_secret_2", 60*1000))
+	assert.Equal(t, []string{"watched_secret_1", "watched_secret_2"}, client.ListSecrets())
+	assert.True(t, client.scheduledMap.Has("watched_secret_2"))
+	cacheSecretInfo, err := client.cacheSecretStoreStrategy.GetCacheSecretInfo("watched_secret_2")
+	assert.Nil(t, err)
+	assert.Equal(t, "value2", cacheSecretInfo.SecretInfo.SecretValue)
+
+	assert.Nil(t, client.RemoveSecret("watched_secret_1"))
+	assert.Equal(t, []string{"watched_secret_2"}, client.ListSecrets())
+	assert.False(t, client.scheduledMap.Has("watched_secret_1"))
+	_, err = client.cacheSecretStoreStrategy.GetCacheSecretInfo("watched_secret_1")
+	assert.NotNil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = client.GetSecretInfo("watched_secret_2")
+		}()
+		go func() {
+			defer wg.Done()
+			_ = client.AddSecret("watched_secret_1", 60*1000)
+			_ = client.RemoveSecret("watched_secret_1")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSecretCacheClient_GetSecretInfoByStage(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("stage_secret", "v2", "current")
+	smc.putStageSecret("stage_secret", "ACSPrevious", "v1", "previous")
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	current, err := client.GetSecretInfo("stage_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "current", current.SecretValue)
+	previous, err := client.GetSecretInfoByStage("stage_secret", "ACSPrevious")
+	assert.Nil(t, err)
+	assert.Equal(t, "previous", previous.SecretValue)
+	_, err = client.GetSecretInfoByStage("stage_secret", "")
+	assert.NotNil(t, err)
+
+	assert.True(t, client.scheduledMap.Has("stage_secret"))
+	assert.True(t, client.scheduledMap.Has(cache.CacheKey("stage_secret", "ACSPrevious")))
+	cacheSecretInfo, err := client.cacheSecretStoreStrategy.(cache.StageSecretCacheStoreStrategy).GetCacheSecretInfoByStage("stage_secret", "ACSPrevious")
+	assert.Nil(t, err)
+	assert.Equal(t, "ACSPrevious", cacheSecretInfo.Stage)
+
+	assert.Nil(t, client.RemoveSecret("stage_secret"))
+	assert.False(t, client.scheduledMap.Has("stage_secret"))
+	assert.False(t, client.scheduledMap.Has(cache.CacheKey("stage_secret", "ACSPrevious")))
+}
+
+func TestSecretCacheClient_RemoveSecretAllStages(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("stage_secret", "v2", "current")
+	smc.putStageSecret("stage_secret", "ACSPrevious", "v1", "previous")
+	client := newMockCacheClient(smc)
+	client.negativeCacheTTL = 60 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfoByStage("stage_secret", "ACSPrevious")
+	assert.Nil(t, err)
+	// 定时刷新任务执行期间会暂时移除定时器
+	client.removeRefreshTask(cache.CacheKey("stage_secret", "ACSPrevious"))
+	_, err = client.GetSecretInfoByStage("stage_secret", "ACSPending")
+	assert.NotNil(t, err)
+	assert.True(t, client.negativeCacheMap.Has(cache.CacheKey("stage_secret", "ACSPending")))
+
+	assert.Nil(t, client.RemoveSecret("stage_secret"))
+	_, err = client.cacheSecretStoreStrategy.(cache.StageSecretCacheStoreStrategy).GetCacheSecretInfoByStage("stage_secret", "ACSPrevious")
+	assert.NotNil(t, err)
+	assert.False(t, client.negativeCacheMap.Has(cache.CacheKey("stage_secret", "ACSPending")))
+	assert.Empty(t, client.cachedStageMap.Keys())
+}
+
+func TestFileCacheSecretStoreStrategy_GetCacheSecretInfoByStage(t *testing.T) {
+	cacheSecretPath, err := ioutil.TempDir("", "secrets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(cacheSecretPath)
+
+	smc := newMockSecretManagerClient()
+	smc.putStageSecret("file_stage_secret", "ACSPrevious", "v1", "previous")
+	client := newMockCacheClient(smc)
+	client.stage = "ACSPrevious"
+	client.cacheSecretStoreStrategy = cache.NewFileCacheSecretStoreStrategy(cacheSecretPath, true, "1234abcd")
+	assert.Nil(t, client.Init())
+	defer client.Close()
+	value, err := client.GetStringValue("file_stage_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "previous", value)
+
+	reloaded := cache.NewFileCacheSecretStoreStrategy(cacheSecretPath, true, "1234abcd")
+	assert.Nil(t, reloaded.Init())
+	cacheSecretInfo, err := reloaded.GetCacheSecretInfoByStage("file_stage_secret", "ACSPrevious")
+	assert.Nil(t, err)
+	assert.Equal(t, "previous", cacheSecretInfo.SecretInfo.SecretValue)
+	assert.Equal(t, "ACSPrevious", cacheSecretInfo.Stage)
+	_, err = reloaded.GetCacheSecretInfo("file_stage_secret")
+	assert.NotNil(t, err)
+}
+
+func TestSecretCacheClient_GetSecretInfoByVersion(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("version_secret", "v2", "value2")
+	smc.putVersionSecret("version_secret", "v1", "value1")
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	info, err := client.GetSecretInfoByVersion("version_secret", "v1")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", info.SecretValue)
+	assert.Equal(t, "v1", info.VersionId)
+	_, err = client.GetSecretInfoByVersion("version_secret", "")
+	assert.NotNil(t, err)
+
+	calls := atomic.LoadInt32(&smc.calls)
+	info, err = client.GetSecretInfoByVersion("version_secret", "v1")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", info.SecretValue)
+	assert.Equal(t, calls, atomic.LoadInt32(&smc.calls))
+	assert.Equal(t, 0, client.scheduledMap.Count())
+
+	current, err := client.GetSecretInfo("version_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value2", current.SecretValue)
+
+	assert.Nil(t, client.RemoveSecret("version_secret"))
+	assert.False(t, client.versionCacheMap.Has(versionCacheKey("version_secret", "v1")))
+}
+
+func TestSecretCacheClient_BoundedVersionCache(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putVersionSecret("version_secret", "v1", "value1")
+	smc.putVersionSecret("version_secret", "v2", "value2")
+	smc.putVersionSecret("version_secret", "v3", "value3")
+	client := newMockCacheClient(smc)
+	client.maxVersionEntries = 2
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfoByVersion("version_secret", "v1")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfoByVersion("version_secret", "v2")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfoByVersion("version_secret", "v1")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfoByVersion("version_secret", "v3")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, client.versionCacheMap.Count())
+	assert.True(t, client.versionCacheMap.Has(versionCacheKey("version_secret", "v1")))
+	assert.False(t, client.versionCacheMap.Has(versionCacheKey("version_secret", "v2")))
+
+	calls := atomic.LoadInt32(&smc.calls)
+	info, err := client.GetSecretInfoByVersion("version_secret", "v2")
+	assert.Nil(t, err)
+	assert.Equal(t, "value2", info.SecretValue)
+	assert.Equal(t, calls+1, atomic.LoadInt32(&smc.calls))
+	assert.Equal(t, 2, client.versionCacheMap.Count())
+}
+
+func TestSecretCacheClient_ParallelPreload(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	client := newMockCacheClient(smc)
+	client.preloadConcurrency = 4
+	for i := 0; i < 8; i++ {
+		secretName := fmt.Sprintf("preload_secret_%d", i)
+		smc.putSecret(secretName, "v1", "value")
+		client.secretTTLMap[secretName] = 60 * 1000
+	}
+	// 前4个请求同时到达后才返回，串行加载时等待超时
+	var mtx sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	arrived := make(chan struct{})
+	var arriveOnce sync.Once
+	smc.setFetchHook(func(secretName string) {
+		mtx.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		if inFlight == client.preloadConcurrency {
+			arriveOnce.Do(func() { close(arrived) })
+		}
+		mtx.Unlock()
+		select {
+		case <-arrived:
+		case <-time.After(5 * time.Second):
+		}
+		mtx.Lock()
+		inFlight--
+		mtx.Unlock()
+	})
+	assert.Nil(t, client.Init())
+	defer client.Close()
+	select {
+	case <-arrived:
+	default:
+		t.Fatal("secrets were not preloaded in parallel")
+	}
+	mtx.Lock()
+	assert.Equal(t, client.preloadConcurrency, maxInFlight)
+	mtx.Unlock()
+	assert.Equal(t, int32(8), atomic.LoadInt32(&smc.calls))
+}
+
+func TestSecretCacheClient_GetSecretInfos(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("batch_secret_1", "v1", "value1")
+	smc.putSecret("batch_secret_2", "v1", "value2")
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	results := client.GetSecretInfos("batch_secret_1", "batch_secret_2", "batch_missing", "batch_secret_1")
+	assert.Equal(t, 3, len(results))
+	assert.Nil(t, results["batch_secret_1"].Err)
+	assert.Equal(t, "value1", results["batch_secret_1"].SecretInfo.SecretValue)
+	assert.Nil(t, results["batch_secret_2"].Err)
+	assert.Equal(t, "value2", results["batch_secret_2"].SecretInfo.SecretValue)
+	assert.NotNil(t, results["batch_missing"].Err)
+	assert.Nil(t, results["batch_missing"].SecretInfo)
+	assert.Equal(t, 0, len(client.GetSecretInfos()))
+}
+
+// blockFetch 阻塞指定凭据的下一次获取，返回请求到达的通知及放行函数
+func blockFetch(smc *mockSecretManagerClient, secretName string) (<-chan struct{}, func()) {
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	smc.setFetchHook(func(name string) {
+		if name == secretName {
+			once.Do(func() {
+				close(fetching)
+				<-release
+			})
+		}
+	})
+	return fetching, func() { close(release) }
+}
+
+func TestSecretCacheClient_Shutdown(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("shutdown_secret", "v1", "value")
+	client := newMockCacheClient(smc)
+	client.secretTTLMap["shutdown_secret"] = 60 * 1000
+	assert.Nil(t, client.Init())
+	assert.Equal(t, 1, client.scheduledMap.Count())
+
+	fetching, release := blockFetch(smc, "shutdown_secret")
+	refreshed := make(chan error, 1)
+	go func() {
+		_, err := client.RefreshNow("shutdown_secret")
+		refreshed <- err
+	}()
+	<-fetching
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- client.Shutdown(context.Background())
+	}()
+	for !client.isClosed() {
+		runtime.Gosched()
+	}
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown must wait for running refresh")
+	default:
+	}
+	assert.False(t, smc.isClosed())
+	release()
+	assert.Nil(t, <-refreshed)
+	assert.Nil(t, <-shutdown)
+	assert.True(t, smc.isClosed())
+	assert.Equal(t, 0, client.scheduledMap.Count())
+
+	_, err := client.GetSecretInfo("shutdown_secret")
+	assert.Equal(t, ErrClientClosed, err)
+	_, err = client.RefreshNow("shutdown_secret")
+	assert.Equal(t, ErrClientClosed, err)
+	assert.Equal(t, ErrClientClosed, client.AddSecret("shutdown_secret", 1000))
+	assert.Equal(t, ErrClientClosed, client.Shutdown(context.Background()))
+	assert.Nil(t, client.Close())
+}
+
+func TestSecretCacheClient_ShutdownDeadline(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("shutdown_deadline_secret", "v1", "value")
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+
+	fetching, release := blockFetch(smc, "shutdown_deadline_secret")
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		client.RefreshNow("shutdown_deadline_secret")
+	}()
+	<-fetching
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, client.Shutdown(ctx))
+	// 正在执行的刷新完成前不关闭KMS客户端
+	assert.False(t, smc.isClosed())
+	release()
+	<-refreshed
+	select {
+	case <-smc.closeCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("resources were not closed after running refresh finished")
+	}
+	assert.Nil(t, client.Close())
+}
+
+func TestSecretCacheClient_UpcomingRefreshes(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("upcoming_secret_1", "v1", "value")
+	smc.putSecret("upcoming_secret_2", "v1", "value")
+	client := newMockCacheClient(smc)
+	client.secretTTLMap["upcoming_secret_1"] = 60 * 1000
+	client.secretTTLMap["upcoming_secret_2"] = 30 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	upcoming := client.UpcomingRefreshes(0)
+	assert.Equal(t, 2, len(upcoming))
+	assert.Equal(t, "upcoming_secret_2", upcoming[0].SecretName)
+	assert.Equal(t, "upcoming_secret_1", upcoming[1].SecretName)
+	assert.Equal(t, utils.StageAcsCurrent, upcoming[0].Stage)
+
+	client.PauseRefresh()
+	assert.True(t, client.scheduler.isPaused())
+	client.ResumeRefresh()
+	assert.False(t, client.scheduler.isPaused())
+
+	assert.Nil(t, client.RemoveSecret("upcoming_secret_2"))
+	upcoming = client.UpcomingRefreshes(0)
+	assert.Equal(t, 1, len(upcoming))
+	assert.Equal(t, "upcoming_secret_1", upcoming[0].SecretName)
+}
+
+func TestSecretCacheClient_Metrics(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("metrics_secret", "v1", "value")
+	client := newMockCacheClient(smc)
+	pm := metrics.NewPrometheusMetrics()
+	client.metrics = pm
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("metrics_secret")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfo("metrics_secret")
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	_, err = pm.WriteTo(&buf)
+	assert.Nil(t, err)
+	output := buf.String()
+	assert.True(t, strings.Contains(output, "secretsmanager_client_cache_hits_total 1\n"))
+	assert.True(t, strings.Contains(output, "secretsmanager_client_cache_misses_total 1\n"))
+	assert.True(t, strings.Contains(output, `secretsmanager_client_refresh_total{secret_name="metrics_secret",outcome="success"} 1`))
+}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordingSpan) End() {
+	s.ended = true
+}
+
+type recordingTracer struct {
+	mtx   sync.Mutex
+	spans []*recordingSpan
+}
+
+func (rt *recordingTracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+	span := &recordingSpan{name: name, attributes: make(map[string]interface{})}
+	rt.mtx.Lock()
+	rt.spans = append(rt.spans, span)
+	rt.mtx.Unlock()
+	return ctx, span
+}
+
+func TestSecretCacheClient_Tracer(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	smc.putSecret("traced_secret", "v1", "value")
+	client := newMockCacheClient(smc)
+	tracer := &recordingTracer{}
+	client.tracer = tracer
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("traced_secret")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfo("traced_secret")
+	assert.Nil(t, err)
+	_, err = client.GetSecretInfo("traced_missing")
+	assert.NotNil(t, err)
+
+	tracer.mtx.Lock()
+	defer tracer.mtx.Unlock()
+	assert.Equal(t, 3, len(tracer.spans))
+	for _, span := range tracer.spans {
+		assert.Equal(t, tracing.SpanGetSecretInfo, span.name)
+		assert.True(t, span.ended)
+		assert.Equal(t, utils.StageAcsCurrent, span.attributes[tracing.AttributeStage])
+	}
+	assert.Equal(t, false, tracer.spans[0].attributes[tracing.AttributeCacheHit])
+	assert.Equal(t, true, tracer.spans[1].attributes[tracing.AttributeCacheHit])
+	assert.Equal(t, "traced_missing", tracer.spans[2].attributes[tracing.AttributeSecretName])
+	assert.NotNil(t, tracer.spans[2].err)
+}
+
+func TestSecretCacheClient_NegativeCache(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	client := newMockCacheClient(smc)
+	client.negativeCacheTTL = 60 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("negative_secret")
+	var teaErr *tea.SDKError
+	assert.True(t, errors.As(err, &teaErr))
+	assert.Equal(t, utils.ErrorCodeForbiddenResourceNotFound, tea.StringValue(teaErr.Code))
+	calls := atomic.LoadInt32(&smc.calls)
+	_, cachedErr := client.GetSecretInfo("negative_secret")
+	assert.Equal(t, err, cachedErr)
+	assert.Equal(t, calls, atomic.LoadInt32(&smc.calls))
+
+	smc.putSecret("negative_secret", "v1", "value")
+	ok, err := client.RefreshNow("negative_secret")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	info, err := client.GetSecretInfo("negative_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", info.SecretValue)
+
+	smc.putError("negative_throttled", &tea.SDKError{Code: tea.String(utils.RejectedThrottling)})
+	_, err = client.GetSecretInfo("negative_throttled")
+	assert.NotNil(t, err)
+	assert.False(t, client.negativeCacheMap.Has("negative_throttled"))
+}
+
+func TestSecretCacheClient_NegativeCacheExpire(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	clock := newFakeClock()
+	client := newMockCacheClient(smc)
+	client.clock = clock.Now
+	client.negativeCacheTTL = 60 * 1000
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	_, err := client.GetSecretInfo("negative_expire_secret")
+	assert.NotNil(t, err)
+	smc.putSecret("negative_expire_secret", "v1", "value")
+	_, err = client.GetSecretInfo("negative_expire_secret")
+	assert.NotNil(t, err)
+	clock.Advance(2 * time.Minute)
+	info, err := client.GetSecretInfo("negative_expire_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", info.SecretValue)
+}
+
+func TestSecretCacheClient_NegativeCacheBounded(t *testing.T) {
+	smc := newMockSecretManagerClient()
+	clock := newFakeClock()
+	client := newMockCacheClient(smc)
+	client.clock = clock.Now
+	client.negativeCacheTTL = 60 * 1000
+	client.maxNegativeCacheEntries = 2
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	for _, secretName := range []string{"negative_bounded_1", "negative_bounded_2", "negative_bounded_3"} {
+		_, err := client.GetSecretInfo(secretName)
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, 2, client.negativeCacheMap.Count())
+	assert.False(t, client.negativeCacheMap.Has("negative_bounded_3"))
+
+	// 达到数量上限后清理已过期的异常
+	clock.Advance(2 * time.Minute)
+	_, err := client.GetSecretInfo("negative_bounded_4")
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, client.negativeCacheMap.Count())
+	assert.True(t, client.negativeCacheMap.Has("negative_bounded_4"))
+}
+
+func TestSecretCacheClient_GetBinaryValue(t *testing.T) {
+	secretData := []byte{0x00, 0x01, 0xfe, 0xff}
+	smc := newMockSecretManagerClient()
+	smc.putBinarySecret("binary_secret", "v1", secretData)
+	smc.putSecret("invalid_binary_secret", "v1", "not base64!")
+	smc.secrets["invalid_binary_secret"].SecretDataType = tea.String(utils.BinaryDataType)
+	client := newMockCacheClient(smc)
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	value, err := client.GetBinaryValue("binary_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, secretData, value)
+	value[0] = 0x7f
+	value, err = client.GetBinaryValue("binary_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, secretData, value)
+
+	_, err = client.GetBinaryValue("invalid_binary_secret")
+	assert.NotNil(t, err)
+}
+
+func TestSecretCacheClient_LegacyBinaryValue(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("raw"))
+	smc := newMockSecretManagerClient()
+	smc.putBinarySecret("binary_secret", "v1", []byte("raw"))
+	client := newMockCacheClient(smc)
+	client.legacyBinaryValue = true
+	assert.Nil(t, client.Init())
+	defer client.Close()
+
+	value, err := client.GetBinaryValue("binary_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte(encoded), value)
+}
+
+func TestFileCacheSecretStoreStrategy_BinaryValue(t *testing.T) {
+	cacheSecretPath, err := ioutil.TempDir("", "secrets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(cacheSecretPath)
+
+	secretData := []byte{0x00, 0x10, 0x20, 0xff}
+	smc := newMockSecretManagerClient()
+	smc.putBinarySecret("file_binary_secret", "v1", secretData)
+	client := newMockCacheClient(smc)
+	client.cacheSecretStoreStrategy = cache.NewFileCacheSecretStoreStrategy(cacheSecretPath, true, "1234abcd")
+	assert.Nil(t, client.Init())
+	defer client.Close()
+	value, err := client.GetBinaryValue("file_binary_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, secretData, value)
+
+	reloaded := cache.NewFileCacheSecretStoreStrategy(cacheSecretPath, true, "1234abcd")
+	assert.Nil(t, reloaded.Init())
+	cacheSecretInfo, err := reloaded.GetCacheSecretInfo("file_binary_secret")
+	assert.Nil(t, err)
+	assert.Equal(t, secretData, cacheSecretInfo.SecretInfo.SecretValueByteBuffer)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+)
+
+// jitterRefreshSecretStrategy 在TTL的refreshAheadFactor比例处提前刷新，并叠加jitterFactor比例的随机抖动，避免大量实例同时刷新
+type jitterRefreshSecretStrategy struct {
+	*defaultRefreshSecretStrategy
+	refreshAheadFactor float64
+	jitterFactor       float64
+	randMtx            sync.Mutex
+	random             *rand.Rand
+}
+
+// NewJitterRefreshSecretStrategy 构建带随机抖动的刷新策略
+// refreshAheadFactor 为提前刷新比例，取值(0, 1]，例如0.8表示在TTL的80%处刷新
+// jitterFactor 为随机抖动比例，取值[0, 1)，例如0.1表示在此基础上随机延后0~10%的TTL，刷新时间不会超过TTL
+func NewJitterRefreshSecretStrategy(jsonTTLPropertyName string, refreshAheadFactor, jitterFactor float64) RefreshSecretStrategy {
+	return &jitterRefreshSecretStrategy{
+		defaultRefreshSecretStrategy: &defaultRefreshSecretStrategy{
+			jsonTTLPropertyName: jsonTTLPropertyName,
+		},
+		refreshAheadFactor: refreshAheadFactor,
+		jitterFactor:       jitterFactor,
+		random:             rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (jrs *jitterRefreshSecretStrategy) Init() error {
+	if jrs.refreshAheadFactor <= 0 || jrs.refreshAheadFactor > 1 {
+		return errors.New(fmt.Sprintf("refreshAheadFactor[%v] must be in (0, 1]", jrs.refreshAheadFactor))
+	}
+	if jrs.jitterFactor < 0 || jrs.jitterFactor >= 1 {
+		return errors.New(fmt.Sprintf("jitterFactor[%v] must be in [0, 1)", jrs.jitterFactor))
+	}
+	return nil
+}
+
+func (jrs *jitterRefreshSecretStrategy) GetNextExecuteTime(secretName string, ttl, offsetTimestamp int64) int64 {
+	now := time.Now().UnixNano() / 1e6
+	delay := jrs.getDelay(ttl)
+	if delay+offsetTimestamp > now {
+		return delay + offsetTimestamp
+	} else {
+		return now + delay
+	}
+}
+
+func (jrs *jitterRefreshSecretStrategy) ParseNextExecuteTime(cacheSecretInfo *models.CacheSecretInfo) int64 {
+	ttl := jrs.ParseTTL(cacheSecretInfo.SecretInfo)
+	if ttl <= 0 {
+		return ttl
+	}
+	return jrs.GetNextExecuteTime(cacheSecretInfo.SecretInfo.SecretName, ttl, cacheSecretInfo.RefreshTimestamp)
+}
+
+// getDelay 计算距离上次刷新的延迟时间，单位MS
+func (jrs *jitterRefreshSecretStrategy) getDelay(ttl int64) int64 {
+	jrs.randMtx.Lock()
+	factor := jrs.refreshAheadFactor + jrs.jitterFactor*jrs.random.Float64()
+	jrs.randMtx.Unlock()
+	if factor > 1 {
+		factor = 1
+	}
+	return int64(float64(ttl) * factor)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitterRefreshSecretStrategy_Init(t *testing.T) {
+	assert.Nil(t, NewJitterRefreshSecretStrategy("ttl", 0.8, 0.1).Init())
+	assert.NotNil(t, NewJitterRefreshSecretStrategy("ttl", 0, 0.1).Init())
+	assert.NotNil(t, NewJitterRefreshSecretStrategy("ttl", 1.2, 0.1).Init())
+	assert.NotNil(t, NewJitterRefreshSecretStrategy("ttl", 0.8, 1).Init())
+}
+
+func TestJitterRefreshSecretStrategy_GetNextExecuteTime(t *testing.T) {
+	strategy := NewJitterRefreshSecretStrategy("ttl", 0.8, 0.1)
+	var ttl int64 = 100000
+	offset := time.Now().UnixNano() / 1e6
+	executeTimes := make(map[int64]bool)
+	for i := 0; i < 100; i++ {
+		executeTime := strategy.GetNextExecuteTime("secret", ttl, offset)
+		assert.True(t, executeTime >= offset+80000)
+		assert.True(t, executeTime <= offset+90000)
+		executeTimes[executeTime] = true
+	}
+	assert.True(t, len(executeTimes) > 1)
+
+	now := time.Now().UnixNano() / 1e6
+	executeTime := strategy.GetNextExecuteTime("secret", ttl, now-2*ttl)
+	assert.True(t, executeTime >= now+80000)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"time"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+)
+
+const (
+	// AutomaticRotationEnabled 凭据已开启自动轮转
+	AutomaticRotationEnabled = "Enabled"
+
+	// DefaultRotationGracePeriod 默认轮转时间后等待新版本生效的时长(毫秒)
+	DefaultRotationGracePeriod int64 = 30 * 1000
+
+	// DefaultRotationFallbackInterval 默认兜底轮询间隔(毫秒)
+	DefaultRotationFallbackInterval int64 = 60 * 60 * 1000
+)
+
+// rotationRefreshSecretStrategy 对开启自动轮转的凭据在NextRotationDate之后的gracePeriod刷新，
+// 同时最多间隔fallbackInterval轮询一次，以便感知手动轮转；未开启自动轮转的凭据沿用默认刷新策略
+type rotationRefreshSecretStrategy struct {
+	*defaultRefreshSecretStrategy
+	gracePeriod      int64
+	fallbackInterval int64
+}
+
+// NewRotationRefreshSecretStrategy 构建感知凭据轮转时间的刷新策略
+// gracePeriod 为轮转时间后等待新版本生效的时长，fallbackInterval 为兜底轮询间隔，单位MS，小于等于0时使用默认值
+func NewRotationRefreshSecretStrategy(jsonTTLPropertyName string, gracePeriod, fallbackInterval int64) RefreshSecretStrategy {
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultRotationGracePeriod
+	}
+	if fallbackInterval <= 0 {
+		fallbackInterval = DefaultRotationFallbackInterval
+	}
+	return &rotationRefreshSecretStrategy{
+		defaultRefreshSecretStrategy: &defaultRefreshSecretStrategy{
+			jsonTTLPropertyName: jsonTTLPropertyName,
+		},
+		gracePeriod:      gracePeriod,
+		fallbackInterval: fallbackInterval,
+	}
+}
+
+func (rrs *rotationRefreshSecretStrategy) ParseNextExecuteTime(cacheSecretInfo *models.CacheSecretInfo) int64 {
+	nextRotationTime := rrs.parseNextRotationTime(cacheSecretInfo.SecretInfo)
+	if nextRotationTime <= 0 {
+		return rrs.defaultRefreshSecretStrategy.ParseNextExecuteTime(cacheSecretInfo)
+	}
+	now := time.Now().UnixNano() / 1e6
+	executeTime := nextRotationTime + rrs.gracePeriod
+	if executeTime <= now {
+		// 已过轮转时间但仍未获取到新的轮转时间，说明轮转尚未完成，等待gracePeriod后重试
+		executeTime = now + rrs.gracePeriod
+	}
+	fallbackTime := cacheSecretInfo.RefreshTimestamp + rrs.fallbackInterval
+	if fallbackTime < now {
+		fallbackTime = now + rrs.fallbackInterval
+	}
+	if fallbackTime < executeTime {
+		return fallbackTime
+	}
+	return executeTime
+}
+
+// parseNextRotationTime 解析下一次轮转时间戳，单位MS，未开启自动轮转或解析失败时返回-1
+func (rrs *rotationRefreshSecretStrategy) parseNextRotationTime(secretInfo *models.SecretInfo) int64 {
+	if secretInfo == nil || secretInfo.AutomaticRotation != AutomaticRotationEnabled || secretInfo.NextRotationDate == "" {
+		return -1
+	}
+	nextRotationDate, err := time.Parse(time.RFC3339, secretInfo.NextRotationDate)
+	if err != nil {
+		return -1
+	}
+	return nextRotationDate.UnixNano() / 1e6
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRotationCacheSecretInfo(automaticRotation string, nextRotationDate time.Time, refreshTimestamp int64) *models.CacheSecretInfo {
+	return &models.CacheSecretInfo{
+		SecretInfo: &models.SecretInfo{
+			SecretName:        "rotation_secret",
+			SecretValue:       "value",
+			AutomaticRotation: automaticRotation,
+			NextRotationDate:  nextRotationDate.Format(time.RFC3339),
+		},
+		RefreshTimestamp: refreshTimestamp,
+	}
+}
+
+func TestRotationRefreshSecretStrategy_ParseNextExecuteTime(t *testing.T) {
+	strategy := NewRotationRefreshSecretStrategy("ttl", 10*1000, 60*60*1000)
+	now := time.Now()
+	nowMillis := now.UnixNano() / 1e6
+
+	// 轮转时间早于兜底轮询时间时，在轮转时间后gracePeriod刷新
+	nextRotation := now.Add(5 * time.Minute).Truncate(time.Second)
+	executeTime := strategy.ParseNextExecuteTime(newRotationCacheSecretInfo(AutomaticRotationEnabled, nextRotation, nowMillis))
+	assert.Equal(t, nextRotation.UnixNano()/1e6+10*1000, executeTime)
+
+	// 轮转时间晚于兜底轮询时间时，按兜底间隔刷新
+	executeTime = strategy.ParseNextExecuteTime(newRotationCacheSecretInfo(AutomaticRotationEnabled, now.Add(48*time.Hour), nowMillis))
+	assert.Equal(t, nowMillis+60*60*1000, executeTime)
+
+	// 已过轮转时间，等待gracePeriod后重试
+	executeTime = strategy.ParseNextExecuteTime(newRotationCacheSecretInfo(AutomaticRotationEnabled, now.Add(-time.Minute), nowMillis))
+	assert.True(t, executeTime >= nowMillis+10*1000)
+	assert.True(t, executeTime < nowMillis+11*1000)
+
+	// 未开启自动轮转时沿用默认策略
+	executeTime = strategy.ParseNextExecuteTime(newRotationCacheSecretInfo("Disabled", nextRotation, nowMillis))
+	assert.Equal(t, int64(-1), executeTime)
+}
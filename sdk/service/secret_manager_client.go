@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/aliyun/credentials-go/credentials"
@@ -14,9 +15,12 @@ import (
 
 	openapiutil "github.com/alibabacloud-go/darabonba-openapi/v2/utils"
 	kms20160120 "github.com/alibabacloud-go/kms-20160120/v3/client"
+	"github.com/alibabacloud-go/tea/dara"
 	"github.com/alibabacloud-go/tea/tea"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/metrics"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/tracing"
 	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
 )
 
@@ -33,6 +37,15 @@ type SecretManagerClient interface {
 	Close() error
 }
 
+// SecretManagerContextClient 是支持context.Context的凭据管理服务客户端接口
+// 调用方可以通过ctx取消请求或设置超时时间
+type SecretManagerContextClient interface {
+	SecretManagerClient
+
+	// GetSecretValueWithContext 获取指定凭据信息，ctx取消或超时后立即返回
+	GetSecretValueWithContext(ctx context.Context, req *kms20160120.GetSecretValueRequest) (*kms20160120.GetSecretValueResponse, error)
+}
+
 // BaseSecretManagerClientBuilder 是基础的SecretManager客户端构建器结构体
 // 用于创建标准的SecretManager客户端构建器实例
 type BaseSecretManagerClientBuilder struct {
@@ -47,6 +60,8 @@ type DefaultSecretManagerClientBuilder struct {
 	backoffStrategy  BackoffStrategy                            // 退避策略
 	configMap        map[*models.RegionInfo]*openapiutil.Config // 地域配置映射
 	customConfigFile string                                     // 自定义配置文件路径
+	metrics          metrics.Metrics                            // 指标采集
+	tracer           tracing.Tracer                             // 链路追踪
 }
 
 // defaultSecretManagerClient 是默认的SecretManager客户端实现
@@ -141,6 +156,22 @@ func (dsb *DefaultSecretManagerClientBuilder) WithCustomConfigFile(customConfigF
 	return dsb
 }
 
+// WithMetrics 设置指标采集
+// 参数metrics用于记录KMS调用耗时、错误码及重试次数
+// 返回构建器本身以支持链式调用
+func (dsb *DefaultSecretManagerClientBuilder) WithMetrics(metrics metrics.Metrics) *DefaultSecretManagerClientBuilder {
+	dsb.metrics = metrics
+	return dsb
+}
+
+// WithTracer 设置链路追踪
+// 参数tracer用于追踪每次KMS调用的地域、终端地址及重试次数
+// 返回构建器本身以支持链式调用
+func (dsb *DefaultSecretManagerClientBuilder) WithTracer(tracer tracing.Tracer) *DefaultSecretManagerClientBuilder {
+	dsb.tracer = tracer
+	return dsb
+}
+
 // Build 构建SecretManager客户端
 // 根据已设置的配置参数创建并返回SecretManagerClient实例
 // 返回实现SecretManagerClient接口的对象
@@ -234,17 +265,26 @@ func (dmc *defaultSecretManagerClient) Init() error {
 }
 
 func (dmc *defaultSecretManagerClient) GetSecretValue(req *kms20160120.GetSecretValueRequest) (*kms20160120.GetSecretValueResponse, error) {
+	return dmc.GetSecretValueWithContext(context.Background(), req)
+}
+
+func (dmc *defaultSecretManagerClient) GetSecretValueWithContext(ctx context.Context, req *kms20160120.GetSecretValueRequest) (*kms20160120.GetSecretValueResponse, error) {
 	var results []*kms20160120.GetSecretValueResponse
 	var errs []error
+	var resultMtx sync.Mutex
 	var wg sync.WaitGroup
 	finished := int32(len(dmc.regionInfos))
-	retryEnd := make(chan struct{})
+	retryCtx, cancel := context.WithTimeout(ctx, time.Duration(utils.RequestWaitingTime)*time.Millisecond)
+	defer cancel()
 	for i, regionInfo := range dmc.regionInfos {
 		if i == 0 {
-			resp, err := dmc.getSecretValue(regionInfo, req)
+			resp, err := dmc.getSecretValue(retryCtx, regionInfo, req, 0)
 			if err == nil {
 				return resp, nil
 			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			logger.GetCommonLogger(utils.ModeName).Errorf("action:getSecretValue, regionInfo:%+v, %+v", regionInfo, err)
 			if !utils.JudgeNeedRecoveryException(err) {
 				return nil, err
@@ -255,27 +295,31 @@ func (dmc *defaultSecretManagerClient) GetSecretValue(req *kms20160120.GetSecret
 		request := &kms20160120.GetSecretValueRequest{}
 		request.SecretName = req.SecretName
 		request.VersionStage = req.VersionStage
+		request.VersionId = req.VersionId
 		request.FetchExtendedConfig = req.FetchExtendedConfig
-		go func(wg *sync.WaitGroup, finished *int32, retryEnd <-chan struct{}) {
-			if resp, err := dmc.retryGetSecretValue(request, regionInfo, retryEnd); err == nil {
+		go func(wg *sync.WaitGroup, finished *int32) {
+			if resp, err := dmc.retryGetSecretValue(retryCtx, request, regionInfo); err == nil {
+				resultMtx.Lock()
 				results = append(results, resp)
+				resultMtx.Unlock()
 				wg.Done()
 			} else {
+				resultMtx.Lock()
 				errs = append(errs, err)
-				for {
-					val := atomic.LoadInt32(finished)
-					if atomic.CompareAndSwapInt32(finished, val, val-1) {
-						break
-					}
-				}
-				if atomic.LoadInt32(finished) == 0 {
+				resultMtx.Unlock()
+				if atomic.AddInt32(finished, -1) == 0 {
 					wg.Done()
 				}
 			}
-		}(&wg, &finished, retryEnd)
+		}(&wg, &finished)
+	}
+	dmc.waitContext(retryCtx, &wg)
+	cancel()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
-	dmc.waitTimeout(&wg, time.Duration(utils.RequestWaitingTime)*time.Millisecond)
-	close(retryEnd)
+	resultMtx.Lock()
+	defer resultMtx.Unlock()
 	if len(results) == 0 {
 		var errStr string
 		for _, err := range errs {
@@ -290,12 +334,49 @@ func (dmc *defaultSecretManagerClient) Close() error {
 	return nil
 }
 
-func (dmc *defaultSecretManagerClient) getSecretValue(regionInfo *models.RegionInfo, req *kms20160120.GetSecretValueRequest) (*kms20160120.GetSecretValueResponse, error) {
+func (dmc *defaultSecretManagerClient) getSecretValue(ctx context.Context, regionInfo *models.RegionInfo, req *kms20160120.GetSecretValueRequest, retryTimes int) (*kms20160120.GetSecretValueResponse, error) {
+	ctx, span := dmc.getTracer().Start(ctx, tracing.SpanGetSecretValue)
+	defer span.End()
+	span.SetAttribute(tracing.AttributeSecretName, tea.StringValue(req.SecretName))
+	span.SetAttribute(tracing.AttributeRegionId, regionInfo.RegionId)
+	span.SetAttribute(tracing.AttributeEndpoint, getRegionEndpoint(regionInfo))
+	span.SetAttribute(tracing.AttributeRetry, retryTimes)
 	client, err := dmc.getClient(regionInfo)
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
-	return client.GetSecretValue(req)
+	start := time.Now()
+	resp, err := client.GetSecretValueWithContext(ctx, req, &dara.RuntimeOptions{})
+	dmc.getMetrics().ObserveKMSCall(regionInfo.RegionId, time.Since(start), metrics.ErrorCode(err))
+	if err != nil {
+		span.RecordError(err)
+	}
+	return resp, err
+}
+
+func (dmc *defaultSecretManagerClient) getTracer() tracing.Tracer {
+	if dmc.DefaultSecretManagerClientBuilder == nil || dmc.tracer == nil {
+		return tracing.NewNoopTracer()
+	}
+	return dmc.tracer
+}
+
+// getRegionEndpoint 获取地域实际调用的终端地址
+func getRegionEndpoint(regionInfo *models.RegionInfo) string {
+	if regionInfo.Endpoint != "" {
+		return regionInfo.Endpoint
+	} else if regionInfo.Vpc {
+		return utils.GetVpcEndpoint(regionInfo.RegionId)
+	}
+	return utils.GetEndpoint(regionInfo.RegionId)
+}
+
+func (dmc *defaultSecretManagerClient) getMetrics() metrics.Metrics {
+	if dmc.DefaultSecretManagerClientBuilder == nil || dmc.metrics == nil {
+		return metrics.NewNoopMetrics()
+	}
+	return dmc.metrics
 }
 
 func (dmc *defaultSecretManagerClient) getClient(regionInfo *models.RegionInfo) (*kms20160120.Client, error) {
@@ -390,34 +471,36 @@ func (dmc *defaultSecretManagerClient) initFromEnv() error {
 	return nil
 }
 
-func (dmc *defaultSecretManagerClient) retryGetSecretValue(req *kms20160120.GetSecretValueRequest, regionInfo *models.RegionInfo, retryEnd <-chan struct{}) (*kms20160120.GetSecretValueResponse, error) {
+func (dmc *defaultSecretManagerClient) retryGetSecretValue(ctx context.Context, req *kms20160120.GetSecretValueRequest, regionInfo *models.RegionInfo) (*kms20160120.GetSecretValueResponse, error) {
 	retryTimes := 0
 	for {
-		select {
-		case <-retryEnd:
-			return nil, errors.New(fmt.Sprintf("action:retryGetSecretValue, retry end"))
-		default:
-			waitTimeExponential := dmc.backoffStrategy.GetWaitTimeExponential(retryTimes)
-			if waitTimeExponential < 0 {
-				return nil, errors.New(fmt.Sprintf("action:retryGetSecretValue, Times limit exceeded"))
-			}
+		waitTimeExponential := dmc.backoffStrategy.GetWaitTimeExponential(retryTimes)
+		if waitTimeExponential < 0 {
+			return nil, errors.New(fmt.Sprintf("action:retryGetSecretValue, Times limit exceeded"))
+		}
 
-			time.Sleep(time.Duration(waitTimeExponential) * time.Millisecond)
+		timer := time.NewTimer(time.Duration(waitTimeExponential) * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.New(fmt.Sprintf("action:retryGetSecretValue, retry end, %+v", ctx.Err()))
+		case <-timer.C:
+		}
 
-			resp, err := dmc.getSecretValue(regionInfo, req)
-			if err == nil {
-				return resp, nil
-			}
-			logger.GetCommonLogger(utils.ModeName).Errorf("action:retryGetSecretValue, regionInfo:%+v, %+v", regionInfo, err)
-			if !utils.JudgeNeedRecoveryException(err) {
-				return nil, err
-			}
-			retryTimes += 1
+		dmc.getMetrics().IncRetry(regionInfo.RegionId)
+		resp, err := dmc.getSecretValue(ctx, regionInfo, req, retryTimes+1)
+		if err == nil {
+			return resp, nil
+		}
+		logger.GetCommonLogger(utils.ModeName).Errorf("action:retryGetSecretValue, regionInfo:%+v, %+v", regionInfo, err)
+		if !utils.JudgeNeedRecoveryException(err) {
+			return nil, err
 		}
+		retryTimes += 1
 	}
 }
 
-func (dmc *defaultSecretManagerClient) waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+func (dmc *defaultSecretManagerClient) waitContext(ctx context.Context, wg *sync.WaitGroup) bool {
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
@@ -426,7 +509,7 @@ func (dmc *defaultSecretManagerClient) waitTimeout(wg *sync.WaitGroup, timeout t
 	select {
 	case <-done:
 		return false
-	case <-time.After(timeout):
+	case <-ctx.Done():
 		return true
 	}
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -1107,7 +1108,7 @@ func TestRetryGetSecretValue(t *testing.T) {
 	// 直接测试retryGetSecretValue方法而不是通过retryGetSecretValueTask
 	go func() {
 		// 调用retryGetSecretValue方法
-		_, _ = client.(*defaultSecretManagerClient).retryGetSecretValue(request, builder.regionInfos[0], nil)
+		_, _ = client.(*defaultSecretManagerClient).retryGetSecretValue(context.Background(), request, builder.regionInfos[0])
 		wg.Done()
 	}()
 
@@ -1155,12 +1156,38 @@ func TestRetryGetSecretValueComprehensive(t *testing.T) {
 	// 验证方法可以被调用（不会抛出异常即为成功）
 	// 允许调用失败，因为我们没有实际的KMS服务
 	// 只要能正确调用方法即可
-	result, err := client.(*defaultSecretManagerClient).retryGetSecretValue(request, builder.regionInfos[0], nil)
+	result, err := client.(*defaultSecretManagerClient).retryGetSecretValue(context.Background(), request, builder.regionInfos[0])
 	assert.True(t, (result == nil && err == nil) || err != nil, "Method should execute without panic")
 
 	t.Log("RetryGetSecretValue comprehensive test passed")
 }
 
+// 测试GetSecretValueWithContext在ctx取消后立即返回
+func TestGetSecretValueWithContextCanceled(t *testing.T) {
+	builder := NewDefaultSecretManagerClientBuilder().WithAccessKey("testAccessKeyId", "testAccessKeySecret")
+	builder.AddRegion("cn-hangzhou")
+	builder.AddRegion("cn-shanghai")
+	client := builder.Build()
+	err := client.Init()
+	assert.Nil(t, err)
+
+	request := &kms20160120.GetSecretValueRequest{
+		SecretName:   tea.String("test-secret"),
+		VersionStage: tea.String("ACSCurrent"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	_, err = client.(*defaultSecretManagerClient).GetSecretValueWithContext(ctx, request)
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	_, err = client.(*defaultSecretManagerClient).retryGetSecretValue(ctx, request, builder.regionInfos[0])
+	assert.NotNil(t, err)
+
+	t.Log("GetSecretValueWithContext canceled test passed")
+}
+
 // 测试CA证书读取功能
 func TestCACertificateReading(t *testing.T) {
 	// 验证CA证书映射表不为空
@@ -0,0 +1,176 @@
+package sqlconnector
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/logger"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+)
+
+// DSNBuilder 根据凭据中的账号和口令构建数据库驱动的DSN
+type DSNBuilder func(credentials *models.RdsCredentials) (string, error)
+
+// AuthErrorJudge 判断建立连接的错误是否为认证失败
+type AuthErrorJudge func(err error) bool
+
+// refreshTimeout 认证失败后共享的凭据刷新的超时时间，刷新不受发起连接的ctx取消影响
+const refreshTimeout = 30 * time.Second
+
+// authErrorMessages 常见数据库认证失败的错误信息
+var authErrorMessages = []string{
+	// MySQL Error 1045
+	"access denied for user",
+	// PostgreSQL 28P01
+	"password authentication failed",
+	// SQL Server 18456
+	"login failed for user",
+}
+
+// Connector 从RDS、Redis、PolarDB托管凭据获取账号口令的driver.Connector，
+// 每次建立新连接时从缓存客户端读取凭据，认证失败时强制刷新凭据并重试一次，凭据轮转后无需重启服务
+type Connector struct {
+	client      *sdk.SecretManagerCacheClient
+	secretName  string
+	driver      driver.Driver
+	dsnBuilder  DSNBuilder
+	isAuthError AuthErrorJudge
+
+	mtx sync.Mutex
+	// 凭据刷新成功的次数，连接期间已刷新过凭据则认证失败后直接重试
+	refreshGeneration uint64
+	// 正在进行的凭据刷新，并发的认证失败共享同一次刷新
+	inflightRefresh *refreshCall
+}
+
+// refreshCall 一次正在进行的凭据刷新
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+var _ driver.Connector = (*Connector)(nil)
+
+// NewConnector 构建Connector，isAuthError为nil时使用DefaultAuthErrorJudge
+func NewConnector(client *sdk.SecretManagerCacheClient, secretName string, drv driver.Driver, dsnBuilder DSNBuilder, isAuthError AuthErrorJudge) (*Connector, error) {
+	if client == nil {
+		return nil, errors.New(fmt.Sprintf("the argument client must not be nil"))
+	}
+	if secretName == "" {
+		return nil, errors.New(fmt.Sprintf("the argument secretName must not be empty"))
+	}
+	if drv == nil {
+		return nil, errors.New(fmt.Sprintf("the argument driver must not be nil"))
+	}
+	if dsnBuilder == nil {
+		return nil, errors.New(fmt.Sprintf("the argument dsnBuilder must not be nil"))
+	}
+	if isAuthError == nil {
+		isAuthError = DefaultAuthErrorJudge
+	}
+	return &Connector{
+		client:      client,
+		secretName:  secretName,
+		driver:      drv,
+		dsnBuilder:  dsnBuilder,
+		isAuthError: isAuthError,
+	}, nil
+}
+
+// Connect 使用当前凭据建立连接，认证失败时强制刷新凭据后重试一次
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mtx.Lock()
+	generation := c.refreshGeneration
+	c.mtx.Unlock()
+	conn, err := c.connect(ctx)
+	if err == nil || !c.isAuthError(err) {
+		return conn, err
+	}
+	logger.GetCommonLogger(utils.ModeName).Infof("secretName:%s authentication failed, refresh secret and retry", c.secretName)
+	if refreshErr := c.refresh(ctx, generation); refreshErr != nil {
+		logger.GetCommonLogger(utils.ModeName).Errorf("action:refreshSecretForConnector, secretName:%s, %+v", c.secretName, refreshErr)
+		return nil, err
+	}
+	return c.connect(ctx)
+}
+
+// refresh 强制刷新凭据，generation之后已有刷新成功时跳过，并发调用共享同一次刷新，
+// 共享的刷新在后台使用独立的超时执行，各调用方只在自己的ctx结束时提前返回
+func (c *Connector) refresh(ctx context.Context, generation uint64) error {
+	c.mtx.Lock()
+	if c.refreshGeneration != generation {
+		c.mtx.Unlock()
+		return nil
+	}
+	call := c.inflightRefresh
+	if call == nil {
+		call = &refreshCall{done: make(chan struct{})}
+		c.inflightRefresh = call
+		go c.runRefresh(call)
+	}
+	c.mtx.Unlock()
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Connector) runRefresh(call *refreshCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	_, call.err = c.client.RefreshNowWithContext(ctx, c.secretName)
+
+	c.mtx.Lock()
+	if call.err == nil {
+		c.refreshGeneration++
+	}
+	c.inflightRefresh = nil
+	c.mtx.Unlock()
+	close(call.done)
+}
+
+func (c *Connector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (c *Connector) connect(ctx context.Context) (driver.Conn, error) {
+	credentials, err := c.client.GetRdsCredentialsWithContext(ctx, c.secretName)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := c.dsnBuilder(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if driverContext, ok := c.driver.(driver.DriverContext); ok {
+		connector, err := driverContext.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return connector.Connect(ctx)
+	}
+	return c.driver.Open(dsn)
+}
+
+// DefaultAuthErrorJudge 根据MySQL、PostgreSQL、SQL Server常见的认证失败错误信息判断
+func DefaultAuthErrorJudge(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, authErrorMessage := range authErrorMessages {
+		if strings.Contains(message, authErrorMessage) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package sqlconnector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	kms "github.com/alibabacloud-go/kms-20160120/v3/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSecretManagerClient struct {
+	mtx      sync.Mutex
+	password string
+	version  int
+	fetches  int
+	// 不为nil时获取凭据值阻塞至关闭
+	block chan struct{}
+}
+
+func (m *mockSecretManagerClient) Init() error {
+	return nil
+}
+
+func (m *mockSecretManagerClient) GetSecretValue(req *kms.GetSecretValueRequest) (*kms.GetSecretValueResponse, error) {
+	m.mtx.Lock()
+	m.fetches++
+	block := m.block
+	m.mtx.Unlock()
+	if block != nil {
+		<-block
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return &kms.GetSecretValueResponse{Body: &kms.GetSecretValueResponseBody{
+		SecretName:     req.SecretName,
+		VersionId:      tea.String(fmt.Sprintf("v%d", m.version)),
+		SecretData:     tea.String(fmt.Sprintf(`{"AccountName":"admin","AccountPassword":"%s"}`, m.password)),
+		SecretDataType: tea.String(utils.TextDataType),
+		SecretType:     tea.String(utils.SecretTypeRds),
+	}}, nil
+}
+
+func (m *mockSecretManagerClient) Close() error {
+	return nil
+}
+
+func (m *mockSecretManagerClient) getFetches() int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.fetches
+}
+
+func (m *mockSecretManagerClient) rotate(password string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.password = password
+	m.version++
+}
+
+type mockDriver struct {
+	mtx      sync.Mutex
+	password string
+	opens    int
+}
+
+func (d *mockDriver) setPassword(password string) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.password = password
+}
+
+func (d *mockDriver) Open(dsn string) (driver.Conn, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.opens++
+	if dsn != "admin:"+d.password {
+		return nil, errors.New("Error 1045: Access denied for user 'admin'")
+	}
+	return &mockConn{}, nil
+}
+
+type mockConn struct {
+}
+
+func (c *mockConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *mockConn) Close() error {
+	return nil
+}
+
+func (c *mockConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func buildDSN(credentials *models.RdsCredentials) (string, error) {
+	return credentials.AccountName + ":" + credentials.AccountPassword, nil
+}
+
+func TestConnector_Connect(t *testing.T) {
+	smc := &mockSecretManagerClient{password: "password1"}
+	client, err := sdk.NewSecretCacheClientBuilder(smc).Build()
+	assert.Nil(t, err)
+	defer client.Close()
+	drv := &mockDriver{password: "password1"}
+	connector, err := NewConnector(client, "rds_secret", drv, buildDSN, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, drv, connector.Driver())
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	assert.Nil(t, db.Ping())
+	assert.Equal(t, 1, drv.opens)
+
+	// 凭据轮转后缓存仍为旧口令，认证失败后刷新凭据并重试
+	smc.rotate("password2")
+	drv.password = "password2"
+	db.SetMaxIdleConns(0)
+	assert.Nil(t, db.Ping())
+	assert.Equal(t, 3, drv.opens)
+
+	// 刷新后仍认证失败则返回原错误
+	drv.password = "password3"
+	err = db.Ping()
+	assert.True(t, DefaultAuthErrorJudge(err))
+}
+
+func TestConnector_ConcurrentAuthFailure(t *testing.T) {
+	smc := &mockSecretManagerClient{password: "password1"}
+	client, err := sdk.NewSecretCacheClientBuilder(smc).Build()
+	assert.Nil(t, err)
+	defer client.Close()
+	drv := &mockDriver{password: "password1"}
+	connector, err := NewConnector(client, "rds_secret", drv, buildDSN, nil)
+	assert.Nil(t, err)
+	_, err = connector.Connect(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, smc.getFetches())
+
+	// 并发的认证失败只刷新一次凭据
+	smc.rotate("password2")
+	drv.setPassword("password2")
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := connector.Connect(context.Background())
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, smc.getFetches())
+}
+
+func TestConnector_RefreshNotCanceledByOtherCaller(t *testing.T) {
+	smc := &mockSecretManagerClient{password: "password1"}
+	client, err := sdk.NewSecretCacheClientBuilder(smc).Build()
+	assert.Nil(t, err)
+	defer client.Close()
+	drv := &mockDriver{password: "password1"}
+	connector, err := NewConnector(client, "rds_secret", drv, buildDSN, nil)
+	assert.Nil(t, err)
+	_, err = connector.Connect(context.Background())
+	assert.Nil(t, err)
+
+	smc.rotate("password2")
+	drv.setPassword("password2")
+	block := make(chan struct{})
+	smc.mtx.Lock()
+	smc.block = block
+	smc.mtx.Unlock()
+
+	// 发起刷新的调用方取消后，其他等待同一次刷新的调用方不受影响
+	canceled, cancel := context.WithCancel(context.Background())
+	canceledErr := make(chan error, 1)
+	go func() {
+		_, err := connector.Connect(canceled)
+		canceledErr <- err
+	}()
+	for smc.getFetches() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	waiterErr := make(chan error, 1)
+	go func() {
+		_, err := connector.Connect(context.Background())
+		waiterErr <- err
+	}()
+	cancel()
+	assert.True(t, DefaultAuthErrorJudge(<-canceledErr))
+	close(block)
+	assert.Nil(t, <-waiterErr)
+	assert.Equal(t, 2, smc.getFetches())
+}
+
+func TestDefaultAuthErrorJudge(t *testing.T) {
+	assert.False(t, DefaultAuthErrorJudge(nil))
+	assert.True(t, DefaultAuthErrorJudge(errors.New(`pq: password authentication failed for user "admin"`)))
+	assert.True(t, DefaultAuthErrorJudge(errors.New("mssql: Login failed for user 'sa'.")))
+	assert.False(t, DefaultAuthErrorJudge(errors.New("dial tcp: connection refused")))
+}
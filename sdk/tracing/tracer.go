@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+)
+
+const (
+	// SpanGetSecretInfo 获取凭据信息
+	SpanGetSecretInfo = "SecretManagerCacheClient.GetSecretInfo"
+	// SpanRefreshSecret 定时刷新凭据
+	SpanRefreshSecret = "SecretManagerCacheClient.RefreshSecret"
+	// SpanGetSecretValue 单次调用KMS获取凭据
+	SpanGetSecretValue = "SecretManagerClient.GetSecretValue"
+
+	AttributeSecretName = "secret.name"
+	AttributeStage      = "secret.stage"
+	AttributeCacheHit   = "cache.hit"
+	AttributeRegionId   = "kms.region_id"
+	AttributeEndpoint   = "kms.endpoint"
+	AttributeRetry      = "kms.retry"
+)
+
+// Tracer 链路追踪接口，参考OpenTelemetry的Span模型，实现需保证并发安全
+type Tracer interface {
+	// Start 开启一个Span，返回携带该Span的ctx，便于下游Span关联
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span 一次操作的追踪记录
+type Span interface {
+	// SetAttribute 设置属性
+	SetAttribute(key string, value interface{})
+
+	// RecordError 记录错误
+	RecordError(err error)
+
+	// End 结束Span
+	End()
+}
+
+// NoopTracer 不做任何追踪
+type NoopTracer struct {
+}
+
+func NewNoopTracer() Tracer {
+	return &NoopTracer{}
+}
+
+func (nt *NoopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct {
+}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {
+}
+
+func (noopSpan) RecordError(err error) {
+}
+
+func (noopSpan) End() {
+}
@@ -0,0 +1,107 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/models"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/service"
+	"github.com/aliyun/alibabacloud-secretsmanager-client-go-v2/sdk/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type recordingSpan struct {
+	name       string
+	parent     string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (rs *recordingSpan) SetAttribute(key string, value interface{}) {
+	rs.attributes[key] = value
+}
+
+func (rs *recordingSpan) RecordError(err error) {
+	rs.err = err
+}
+
+func (rs *recordingSpan) End() {
+	rs.ended = true
+}
+
+type recordingTracer struct {
+	mtx   sync.Mutex
+	spans []*recordingSpan
+}
+
+func (rt *recordingTracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+	span := &recordingSpan{name: name, attributes: make(map[string]interface{})}
+	if parent, ok := ctx.Value(spanKey{}).(*recordingSpan); ok {
+		span.parent = parent.name
+	}
+	rt.mtx.Lock()
+	rt.spans = append(rt.spans, span)
+	rt.mtx.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (rt *recordingTracer) find(name string) *recordingSpan {
+	rt.mtx.Lock()
+	defer rt.mtx.Unlock()
+	for _, span := range rt.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestNoopTracer(t *testing.T) {
+	ctx := context.WithValue(context.Background(), spanKey{}, "parent")
+	spanCtx, span := tracing.NewNoopTracer().Start(ctx, tracing.SpanGetSecretInfo)
+	assert.Equal(t, ctx, spanCtx)
+	span.SetAttribute(tracing.AttributeSecretName, "secret")
+	span.RecordError(errors.New("failed"))
+	span.End()
+}
+
+func TestTracer_CacheLookupAndKMSCall(t *testing.T) {
+	tracer := &recordingTracer{}
+	// 不可达的终端地址，KMS调用立即失败
+	secretManagerClient := service.NewDefaultSecretManagerClientBuilder().
+		WithAccessKey("accessKeyId", "accessKeySecret").
+		AddRegionInfo(&models.RegionInfo{RegionId: "cn-hangzhou", Endpoint: "127.0.0.1:1"}).
+		WithBackoffStrategy(service.NewFullJitterBackoffStrategy(1, 10, 10)).
+		WithTracer(tracer).
+		Build()
+	client, err := sdk.NewSecretCacheClientBuilder(secretManagerClient).WithTracer(tracer).Build()
+	assert.Nil(t, err)
+	defer client.Close()
+
+	_, err = client.GetSecretInfo("traced_secret")
+	assert.NotNil(t, err)
+
+	lookup := tracer.find(tracing.SpanGetSecretInfo)
+	assert.NotNil(t, lookup)
+	assert.True(t, lookup.ended)
+	assert.Equal(t, "traced_secret", lookup.attributes[tracing.AttributeSecretName])
+	assert.Equal(t, "ACSCurrent", lookup.attributes[tracing.AttributeStage])
+	assert.Equal(t, false, lookup.attributes[tracing.AttributeCacheHit])
+	assert.NotNil(t, lookup.err)
+
+	kmsCall := tracer.find(tracing.SpanGetSecretValue)
+	assert.NotNil(t, kmsCall)
+	assert.True(t, kmsCall.ended)
+	assert.Equal(t, tracing.SpanGetSecretInfo, kmsCall.parent)
+	assert.Equal(t, "traced_secret", kmsCall.attributes[tracing.AttributeSecretName])
+	assert.Equal(t, "cn-hangzhou", kmsCall.attributes[tracing.AttributeRegionId])
+	assert.Equal(t, "127.0.0.1:1", kmsCall.attributes[tracing.AttributeEndpoint])
+	assert.Equal(t, 0, kmsCall.attributes[tracing.AttributeRetry])
+	assert.NotNil(t, kmsCall.err)
+}
@@ -46,6 +46,19 @@ func JudgeNeedRecoveryException(err error) bool {
 	return false
 }
 
+// JudgeNegativeCacheException 根据Client异常判断是否为可缓存的不可重试异常，如凭据不存在或无访问权限
+func JudgeNegativeCacheException(err error) bool {
+	var errorCode string
+	var sdkErr *dara.SDKError
+	var teaErr *tea.SDKError
+	if errors.As(err, &sdkErr) {
+		errorCode = tea.StringValue(sdkErr.GetCode())
+	} else if errors.As(err, &teaErr) {
+		errorCode = tea.StringValue(teaErr.Code)
+	}
+	return ErrorCodeForbiddenResourceNotFound == errorCode || ErrorCodeForbiddenNoPermission == errorCode
+}
+
 // isConnectionError 判断是否为连接错误
 func isConnectionError(err net.Error) bool {
 	errStr := err.Error()
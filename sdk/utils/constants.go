@@ -4,6 +4,9 @@ const (
 	// StageAcsCurrent 当前stage
 	StageAcsCurrent = "ACSCurrent"
 
+	// StageAcsPrevious 上一版本stage
+	StageAcsPrevious = "ACSPrevious"
+
 	// IvLength 随机IV字节长度
 	IvLength = 16
 
@@ -37,6 +40,12 @@ const (
 	// ErrorCodeForbiddenInDebt TeaException 欠费errorCode
 	ErrorCodeForbiddenInDebt = "Forbidden.InDebt"
 
+	// ErrorCodeForbiddenResourceNotFound TeaException 凭据不存在errorCode
+	ErrorCodeForbiddenResourceNotFound = "Forbidden.ResourceNotFound"
+
+	// ErrorCodeForbiddenNoPermission TeaException 无访问权限errorCode
+	ErrorCodeForbiddenNoPermission = "Forbidden.NoPermission"
+
 	// VariableCacheClientRegionIdKey 地域ID配置键名
 	VariableCacheClientRegionIdKey = "cache_client_region_id"
 
@@ -91,6 +100,24 @@ const (
 	// BinaryDataType 凭据二进制数据类型
 	BinaryDataType = "binary"
 
+	// SecretTypeGeneric 通用凭据类型
+	SecretTypeGeneric = "Generic"
+
+	// SecretTypeRds RDS托管凭据类型
+	SecretTypeRds = "Rds"
+
+	// SecretTypeRedis Redis托管凭据类型
+	SecretTypeRedis = "Redis"
+
+	// SecretTypePolarDB PolarDB托管凭据类型
+	SecretTypePolarDB = "PolarDB"
+
+	// SecretTypeRAMCredentials RAM托管凭据类型
+	SecretTypeRAMCredentials = "RAMCredentials"
+
+	// SecretTypeECS ECS托管凭据类型
+	SecretTypeECS = "ECS"
+
 	// ModeName 模块名称
 	ModeName = "SecretsManagerClientV2"
 
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/base64"
 	"errors"
 	"strings"
 )
@@ -32,3 +33,8 @@ func ParseBool(obj interface{}) (bool, error) {
 	}
 	return false, errors.New("parse bool failed")
 }
+
+// DecodeBinarySecretValue 将KMS返回的base64编码的二进制凭据值解码为原始字节
+func DecodeBinarySecretValue(secretValue string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(secretValue)
+}